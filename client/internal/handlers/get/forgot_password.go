@@ -0,0 +1,41 @@
+package get
+
+import (
+	"client/internal/lib/log/sl"
+	"client/internal/storage/mode"
+	"client/internal/views/pages"
+	"log/slog"
+	"net/http"
+)
+
+func ForgotPasswordHandler(log *slog.Logger, m *mode.ModeStorage) http.HandlerFunc {
+	const op = "get.ForgotPasswordHandler"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Debug("ForgotPasswordHandler called")
+
+		c := pages.ForgotPassword(m.IsDarkMode(r))
+		err := c.Render(r.Context(), w)
+		if err != nil {
+			log.Error("Failed to render page", sl.Err(err))
+			return
+		}
+	}
+}
+
+func ResetPasswordHandler(log *slog.Logger, m *mode.ModeStorage) http.HandlerFunc {
+	const op = "get.ResetPasswordHandler"
+	log = log.With(slog.String("op", op))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Debug("ResetPasswordHandler called")
+
+		c := pages.ResetPassword(m.IsDarkMode(r))
+		err := c.Render(r.Context(), w)
+		if err != nil {
+			log.Error("Failed to render page", sl.Err(err))
+			return
+		}
+	}
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE password_reset_codes (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash VARCHAR(255) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    used BOOLEAN DEFAULT FALSE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX password_reset_codes_user_id_idx ON password_reset_codes (user_id);
+
+-- tokens of the user issued before revoked_before are rejected
+CREATE TABLE user_token_revocations (
+    user_id VARCHAR(255) PRIMARY KEY NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    revoked_before TIMESTAMP NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_token_revocations;
+DROP TABLE password_reset_codes;
+-- +goose StatementEnd
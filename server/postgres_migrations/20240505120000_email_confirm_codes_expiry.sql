@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- old codes never expire and are stored in plain text,
+-- users that didn't verify email yet can request new code with ResendVerification
+DELETE FROM email_confirm_tokens;
+
+ALTER TABLE email_confirm_tokens DROP CONSTRAINT email_confirm_tokens_token_key;
+ALTER TABLE email_confirm_tokens RENAME COLUMN token TO code_hash;
+ALTER TABLE email_confirm_tokens
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN expires_at TIMESTAMP NOT NULL,
+    ADD COLUMN created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX email_confirm_tokens_user_id_idx ON email_confirm_tokens (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM email_confirm_tokens;
+
+DROP INDEX email_confirm_tokens_user_id_idx;
+ALTER TABLE email_confirm_tokens
+    DROP COLUMN created_at,
+    DROP COLUMN expires_at,
+    DROP COLUMN attempts;
+ALTER TABLE email_confirm_tokens RENAME COLUMN code_hash TO token;
+ALTER TABLE email_confirm_tokens ADD CONSTRAINT email_confirm_tokens_token_key UNIQUE (token);
+-- +goose StatementEnd
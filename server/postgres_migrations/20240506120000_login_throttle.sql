@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- failed logins per account (user:<id>) and per client ip (ip:<addr>)
+CREATE TABLE login_throttle (
+    key VARCHAR(255) PRIMARY KEY NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    blocked_until TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_throttle;
+-- +goose StatementEnd
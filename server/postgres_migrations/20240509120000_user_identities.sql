@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- accounts of users in OpenID Connect providers
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
+
+-- started logins with providers, state is single use
+CREATE TABLE oidc_login_states (
+    state VARCHAR(255) PRIMARY KEY NOT NULL,
+    provider VARCHAR(255) NOT NULL,
+    nonce VARCHAR(255) NOT NULL,
+    code_verifier VARCHAR(255) NOT NULL,
+    -- set when logged in user links provider to the account
+    user_id VARCHAR(255) REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE oidc_login_states;
+DROP TABLE user_identities;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- bots are users without password owned by another user, they use api keys
+ALTER TABLE users
+    ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN owner_id VARCHAR(255) REFERENCES users (id) ON DELETE CASCADE;
+
+CREATE TABLE api_keys (
+    id VARCHAR(255) PRIMARY KEY NOT NULL,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    -- first characters of the key, so user can recognize it
+    prefix VARCHAR(32) NOT NULL,
+    key_hash VARCHAR(255) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_keys;
+DELETE FROM users WHERE is_bot = TRUE;
+ALTER TABLE users
+    DROP COLUMN owner_id,
+    DROP COLUMN is_bot;
+-- +goose StatementEnd
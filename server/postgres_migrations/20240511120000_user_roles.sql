@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- first admin has to be set manually: UPDATE users SET role = 'admin' WHERE username = '...';
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin')),
+    ADD COLUMN suspended_at TIMESTAMP,
+    ADD COLUMN suspend_reason TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN suspend_reason,
+    DROP COLUMN suspended_at,
+    DROP COLUMN role;
+-- +goose StatementEnd
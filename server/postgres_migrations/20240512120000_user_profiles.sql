@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_profiles (
+    user_id VARCHAR(255) PRIMARY KEY NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    display_name VARCHAR(64) NOT NULL DEFAULT '',
+    bio TEXT NOT NULL DEFAULT '',
+    -- reference to avatar in blob storage, auth service doesn't store images
+    avatar_ref VARCHAR(512) NOT NULL DEFAULT '',
+    time_zone VARCHAR(64) NOT NULL DEFAULT '',
+    status_text VARCHAR(128) NOT NULL DEFAULT '',
+    status_expires_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_profiles;
+-- +goose StatementEnd
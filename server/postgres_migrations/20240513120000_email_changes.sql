@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- new email of the user is stored here until it is confirmed with code sent to it
+CREATE TABLE email_changes (
+    user_id VARCHAR(255) PRIMARY KEY NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    new_email VARCHAR(255) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE email_changes;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- users with deleted_at are pending deletion, they are purged after grace period
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- events for other services (e.g. rooms and chat remove data of deleted users),
+-- services read them in order of id and remember last read one
+CREATE TABLE user_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(32) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- last user event handled by each consumer
+CREATE TABLE user_event_cursors (
+    consumer VARCHAR(64) PRIMARY KEY NOT NULL,
+    last_event_id BIGINT NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_event_cursors;
+DROP TABLE user_events;
+DROP INDEX users_deleted_at_idx;
+ALTER TABLE users DROP COLUMN deleted_at;
+-- +goose StatementEnd
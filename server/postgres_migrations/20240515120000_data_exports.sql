@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- export of personal data of the user, ZIP file is stored on disk of auth service
+-- and can be downloaded with code sent to email of the user until expires_at
+CREATE TABLE data_exports (
+    id VARCHAR(255) PRIMARY KEY NOT NULL,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    file_path TEXT NOT NULL DEFAULT '',
+    code_hash VARCHAR(64) NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX data_exports_user_id_idx ON data_exports (user_id, created_at);
+CREATE INDEX data_exports_expires_at_idx ON data_exports (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE data_exports;
+-- +goose StatementEnd
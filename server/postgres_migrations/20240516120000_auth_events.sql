@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- security audit log, rows can't be changed or deleted (even when user is purged),
+-- so there is no foreign key to users
+CREATE TABLE auth_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL DEFAULT '', -- empty for anonymous clients and background jobs
+    target_id VARCHAR(255) NOT NULL DEFAULT '',
+    ip VARCHAR(255) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX auth_events_actor_id_idx ON auth_events (actor_id, id);
+CREATE INDEX auth_events_target_id_idx ON auth_events (target_id, id);
+CREATE INDEX auth_events_ip_idx ON auth_events (ip, id);
+CREATE INDEX auth_events_created_at_idx ON auth_events (created_at);
+
+CREATE FUNCTION auth_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'auth_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER auth_events_append_only
+BEFORE UPDATE OR DELETE ON auth_events
+FOR EACH ROW EXECUTE FUNCTION auth_events_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE auth_events;
+DROP FUNCTION auth_events_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- trigram indexes are used by SearchUsers for both prefix (LIKE 'q%') and fuzzy (%) matching
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX users_username_trgm_idx ON users USING GIN (lower(username) gin_trgm_ops);
+CREATE INDEX user_profiles_display_name_trgm_idx ON user_profiles USING GIN (lower(display_name) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX user_profiles_display_name_trgm_idx;
+DROP INDEX users_username_trgm_idx;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_blocks (
+    blocker_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    blocked_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+
+-- to find who blocked the user (e.g. when message is sent)
+CREATE INDEX user_blocks_blocked_id_idx ON user_blocks (blocked_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_blocks;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- invitation codes for invite-only registration, only hash of the code is stored
+CREATE TABLE invitations (
+    id VARCHAR(255) PRIMARY KEY NOT NULL,
+    code_hash VARCHAR(255) NOT NULL UNIQUE,
+    issuer_id VARCHAR(255) REFERENCES users (id) ON DELETE SET NULL,
+    max_uses INT NOT NULL,
+    uses INT NOT NULL DEFAULT 0,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX invitations_issuer_id_idx ON invitations (issuer_id);
+
+-- invitation used to sign up, NULL if it wasn't needed
+ALTER TABLE users ADD COLUMN invitation_id VARCHAR(255) REFERENCES invitations (id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN invitation_id;
+DROP TABLE invitations;
+-- +goose StatementEnd
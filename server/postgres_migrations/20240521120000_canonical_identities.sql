@@ -0,0 +1,76 @@
+-- +goose Up
+-- +goose StatementBegin
+-- canonical forms are used to check uniqueness and to find users:
+-- NFKC with case folding for usernames and lowercased domain for emails.
+-- lower() differs from case folding only for few non-ascii characters (e.g. 'ß'),
+-- such usernames are recomputed once by auth service sync_identities command
+ALTER TABLE users ADD COLUMN username_canonical VARCHAR(255);
+ALTER TABLE users ADD COLUMN email_canonical VARCHAR(255);
+
+-- account which canonical username or email is already used by older account,
+-- it is left out of unique index until admin renames or merges it
+ALTER TABLE users ADD COLUMN username_collision BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN email_collision BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE users SET
+    username_canonical = lower(normalize(username, NFKC)),
+    email_canonical = CASE
+        WHEN position('@' in email) = 0 THEN lower(email)
+        ELSE substring(email from '^(.*@)') || lower(substring(email from '@([^@]*)$'))
+    END;
+
+ALTER TABLE users ALTER COLUMN username_canonical SET NOT NULL;
+ALTER TABLE users ALTER COLUMN email_canonical SET NOT NULL;
+
+-- accounts that were registered separately but have the same canonical username or email.
+-- Sign up didn't set created_at before, so such accounts have zero timestamp and their
+-- order is ambiguous: account with confirmed email goes first, then it is decided by id
+CREATE TABLE identity_collisions (
+    kind VARCHAR(16) NOT NULL, -- username or email
+    canonical VARCHAR(255) NOT NULL,
+    user_ids VARCHAR(255)[] NOT NULL, -- oldest account first
+    detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO identity_collisions (kind, canonical, user_ids)
+SELECT 'username', username_canonical, array_agg(id ORDER BY created_at, confirmed_email DESC NULLS LAST, id)
+FROM users GROUP BY username_canonical HAVING count(*) > 1
+UNION ALL
+SELECT 'email', email_canonical, array_agg(id ORDER BY created_at, confirmed_email DESC NULLS LAST, id)
+FROM users GROUP BY email_canonical HAVING count(*) > 1;
+
+-- the oldest account keeps its username and email, so nobody else can take them
+UPDATE users SET username_collision = TRUE
+FROM identity_collisions c
+WHERE c.kind = 'username' AND users.id = ANY(c.user_ids[2:]);
+
+UPDATE users SET email_collision = TRUE
+FROM identity_collisions c
+WHERE c.kind = 'email' AND users.id = ANY(c.user_ids[2:]);
+
+DO $$
+DECLARE
+    c RECORD;
+BEGIN
+    FOR c IN SELECT * FROM identity_collisions ORDER BY kind, canonical LOOP
+        RAISE WARNING '% collision "%": users %', c.kind, c.canonical, c.user_ids;
+    END LOOP;
+END;
+$$;
+
+CREATE UNIQUE INDEX users_username_canonical_key ON users (username_canonical) WHERE NOT username_collision;
+CREATE UNIQUE INDEX users_email_canonical_key ON users (email_canonical) WHERE NOT email_collision;
+
+-- users are found by canonical forms including colliding accounts, unique indexes can't be used for it
+CREATE INDEX users_username_canonical_idx ON users (username_canonical);
+CREATE INDEX users_email_canonical_idx ON users (email_canonical);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE identity_collisions;
+ALTER TABLE users DROP COLUMN email_collision;
+ALTER TABLE users DROP COLUMN username_collision;
+ALTER TABLE users DROP COLUMN email_canonical;
+ALTER TABLE users DROP COLUMN username_canonical;
+-- +goose StatementEnd
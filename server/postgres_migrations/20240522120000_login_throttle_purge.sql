@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- rows with old failures are purged periodically
+CREATE INDEX login_throttle_last_failure_at_idx ON login_throttle (last_failure_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX login_throttle_last_failure_at_idx;
+-- +goose StatementEnd
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"auth_service/internal/client/chat"
 	"auth_service/internal/client/notifications"
+	"auth_service/internal/client/rooms"
 	"auth_service/internal/config"
 	"auth_service/internal/lib/logger/slogpretty"
+	"auth_service/internal/lib/oidc"
+	"auth_service/internal/lib/password"
 	"auth_service/internal/lib/token"
+	"auth_service/internal/lib/validation"
 	"auth_service/internal/service"
 	"auth_service/internal/storage/sql/postgres"
 	"context"
@@ -50,7 +55,12 @@ func main() {
 	// create storage and log
 	storage := postgres.New(db)
 	log := setupLogger(cfg.Env)
-	tokenManager := token.NewManager(cfg.Tokens.TokenSecret, cfg.Tokens.TokenTTL)
+	keyring := mustLoadKeyring(&cfg.Tokens)
+	tokenManager := token.NewManager(keyring, cfg.Tokens.TokenTTL)
+	oidcProviders := newOIDCProviders(cfg.OIDC.Providers)
+	passwords := newPasswordManager(&cfg.PasswordHashing)
+	passwordPolicy := mustLoadPasswordPolicy(&cfg.PasswordPolicy)
+	mustCheckRegistration(&cfg.Registration)
 
 	// connect to another services
 	notificationManager, err := notifications.Connect(log, cfg.OtherServices.NotificationServiceURL, &cfg.OtherServices.NotificationsCert)
@@ -58,9 +68,41 @@ func main() {
 		log.Error("failed to connect to notifications service", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	roomsClient, err := rooms.Connect(log, cfg.OtherServices.RoomsServiceURL, &cfg.OtherServices.RoomsCert)
+	if err != nil {
+		log.Error("failed to connect to rooms service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	chatClient, err := chat.Connect(log, cfg.OtherServices.ChatServiceURL, &cfg.OtherServices.ChatCert)
+	if err != nil {
+		log.Error("failed to connect to chat service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
 	// create public auth service
-	s := service.New(log, storage, storage, tokenManager, notificationManager)
+	// postgres storage implements every storage of service
+	storages := service.Storages{
+		User:          storage,
+		EmailToken:    storage,
+		RefreshToken:  storage,
+		RevokedToken:  storage,
+		PasswordReset: storage,
+		LoginThrottle: storage,
+		TwoFactor:     storage,
+		Session:       storage,
+		UserIdentity:  storage,
+		APIKey:        storage,
+		Profile:       storage,
+		EmailChange:   storage,
+		UserEvent:     storage,
+		DataExport:    storage,
+		AuthEvent:     storage,
+		UserBlock:     storage,
+		LoginCode:     storage,
+		Invitation:    storage,
+	}
+
+	s := service.New(log, cfg, storages, tokenManager, passwords, passwordPolicy, oidcProviders, notificationManager, roomsClient, chatClient)
 
 	loggingOpts := []logging.Option{
 		logging.WithLogOnEvents(
@@ -86,6 +128,14 @@ func main() {
 	), grpc.Creds(creds))
 
 	service.Register(gRPCServer, s)
+	service.RegisterAdmin(gRPCServer, s)
+
+	// Purge users after deletion grace period, expired data exports and login throttles
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go s.PurgeDeletedUsersLoop(purgeCtx)
+	go s.PurgeDataExportsLoop(purgeCtx)
+	go s.PurgeLoginThrottleLoop(purgeCtx)
 
 	// Start gRPC server
 	go func() {
@@ -101,6 +151,23 @@ func main() {
 		}
 	}()
 
+	// Reload signing keys on SIGHUP
+	if cfg.Tokens.KeysDir != "" {
+		go func() {
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+
+			for range hup {
+				if err := keyring.Reload(); err != nil {
+					log.Error("failed to reload signing keys", slog.String("error", err.Error()))
+					continue
+				}
+
+				log.Info("Reloaded signing keys", slog.String("active_kid", keyring.Active().ID))
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
@@ -108,6 +175,7 @@ func main() {
 	<-stop
 
 	gRPCServer.GracefulStop()
+	s.StopDataExports()
 	log.Info("Gracefully stopped service")
 }
 
@@ -174,3 +242,93 @@ func mustLoadTLSCreds(ca, crt, key string) credentials.TransportCredentials {
 
 	return credentials.NewTLS(c)
 }
+
+func mustLoadKeyring(cfg *config.Tokens) *token.Keyring {
+	if cfg.KeysDir != "" {
+		// old keys must be kept until tokens signed with them expire
+		keyring, err := token.LoadKeyring(cfg.KeysDir, cfg.TokenTTL)
+		if err != nil {
+			panic(fmt.Sprintf("failed to load token signing keys: %v", err))
+		}
+
+		return keyring
+	}
+
+	if cfg.SigningAlg == token.AlgHS256 {
+		if cfg.TokenSecret == "" {
+			panic("token secret is required for HS256 signing")
+		}
+
+		return token.NewKeyring(token.NewHMACKey(cfg.KeyID, []byte(cfg.TokenSecret)))
+	}
+
+	key, err := token.LoadKey(cfg.KeyID, cfg.SigningAlg, cfg.PrivateKeyPath)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load token signing key: %v", err))
+	}
+
+	return token.NewKeyring(key)
+}
+
+func newOIDCProviders(cfgs []config.OIDCProvider) map[string]service.OIDCProvider {
+	providers := make(map[string]service.OIDCProvider, len(cfgs))
+	for _, c := range cfgs {
+		if _, ok := providers[c.Name]; ok {
+			panic("duplicate oidc provider: " + c.Name)
+		}
+
+		providers[c.Name] = oidc.NewProvider(oidc.Config{
+			Issuer:       c.Issuer,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RedirectURL:  c.RedirectURL,
+			Scopes:       c.Scopes,
+		}, nil)
+	}
+	return providers
+}
+
+func mustLoadPasswordPolicy(cfg *config.PasswordPolicy) *validation.PasswordPolicy {
+	policy, err := validation.NewPasswordPolicy(cfg.MinLength, cfg.MaxLength, cfg.MinCharClasses, cfg.CommonPasswordsPath)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load common passwords: %v", err))
+	}
+
+	return policy
+}
+
+// mustCheckRegistration panics if registration mode is unknown,
+// so misconfigured deployment doesn't become open by mistake
+func mustCheckRegistration(cfg *config.Registration) {
+	switch cfg.Mode {
+	case config.RegistrationOpen, config.RegistrationInviteOnly:
+	case config.RegistrationDomainAllowlist:
+		if len(cfg.AllowedDomains) == 0 {
+			panic("registration mode is domain_allowlist, but no domains are allowed")
+		}
+	default:
+		panic("unknown registration mode: " + cfg.Mode)
+	}
+}
+
+// newPasswordManager creates manager that hashes new passwords with configured algorithm,
+// hashes of other one are still accepted and replaced on login
+func newPasswordManager(cfg *config.PasswordHashing) *password.Manager {
+	argon := &password.Argon2id{
+		Time:       cfg.Argon2id.Time,
+		Memory:     cfg.Argon2id.Memory,
+		Threads:    cfg.Argon2id.Threads,
+		KeyLength:  cfg.Argon2id.KeyLength,
+		SaltLength: cfg.Argon2id.SaltLength,
+	}
+	bc := &password.Bcrypt{Cost: cfg.BcryptCost}
+
+	switch cfg.Algorithm {
+	case "argon2id":
+		return password.NewManager(argon, bc)
+	case "bcrypt":
+		return password.NewManager(bc, argon)
+	default:
+		panic("unknown password hashing algorithm: " + cfg.Algorithm)
+	}
+}
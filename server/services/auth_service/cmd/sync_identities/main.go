@@ -0,0 +1,42 @@
+// Command sync_identities fixes canonical usernames and emails computed by migration 20240521120000_canonical_identities,
+// it must be run once after the migration (see postgres_sync_identities in Makefile).
+// Collisions are only reported, such accounts must be renamed or merged by admin.
+package main
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/storage/sql/postgres"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	cfg := config.MustLoad()
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	// open db
+	db, err := sqlx.Open("postgres", cfg.Storage.PostgresURl)
+	if err != nil {
+		panic(fmt.Sprintf("failed to open db (%s): %v", cfg.Storage.PostgresURl, err))
+	}
+	defer db.Close()
+
+	storage := postgres.New(db)
+
+	collisions, err := storage.SyncCanonicalIdentities(context.Background())
+	for _, id := range collisions {
+		log.Warn("username or email of user collides with other user", slog.String("user_id", id))
+	}
+	if err != nil {
+		log.Error("failed to sync canonical usernames and emails", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	log.Info("Canonical usernames and emails are synced", slog.Int("collisions", len(collisions)))
+}
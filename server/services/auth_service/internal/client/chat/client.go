@@ -0,0 +1,90 @@
+package chat
+
+import (
+	"auth_service/internal/config"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/zumosik/grpc_chat_protos/go/chat"
+	"google.golang.org/grpc"
+)
+
+// tokenMetadataKey is metadata key chat service reads token of the user from
+const tokenMetadataKey = "token"
+
+type Client struct {
+	l      *slog.Logger
+	client chat.ChatServiceClient
+}
+
+func Connect(log *slog.Logger, addr string, certCfg *config.CertsConfig) (*Client, error) {
+
+	pemServerCA, err := os.ReadFile(certCfg.CaPath)
+	if err != nil {
+		log.Error("failed to load CA cert", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(pemServerCA) {
+		log.Error("failed to append CA cert")
+		return nil, err
+	}
+
+	clientCert, err := tls.LoadX509KeyPair(certCfg.CertPath, certCfg.KeyPath)
+	if err != nil {
+		log.Error("failed to load client cert", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      certPool,
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := chat.NewChatServiceClient(conn)
+
+	c := &Client{
+		client: client,
+		l:      log,
+	}
+
+	log.Info("Connected to chat service", slog.String("address", addr))
+
+	return c, nil
+}
+
+// ExportUserData returns JSON with data of the user token belongs to (authored messages),
+// chat service sends it in chunks
+func (c *Client) ExportUserData(ctx context.Context, token []byte) ([]byte, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, tokenMetadataKey, string(token))
+
+	stream, err := c.client.ExportUserData(ctx, &chat.ExportUserDataRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, resp.GetChunk()...)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/zumosik/grpc_chat_protos/go/notifications"
 	"google.golang.org/grpc"
@@ -82,3 +83,95 @@ func (c *Client) SendEmailConfirmationEmail(ctx context.Context, token, emailTo
 
 	return nil
 }
+
+// SendPasswordResetEmail can take few seconds to complete.
+func (c *Client) SendPasswordResetEmail(ctx context.Context, code, emailTo string) error {
+	resp, err := c.client.SendNotification(ctx, &notifications.NotificationRequest{
+		Notification: &notifications.NotificationRequest_PasswordReset_{
+			PasswordReset: &notifications.NotificationRequest_PasswordReset{
+				Email: emailTo,
+				Code:  code,
+			}},
+	})
+	if err != nil {
+		return err
+	}
+
+	c.l.Debug("Email sent",
+		slog.String("method", "SendPasswordResetEmail"),
+		slog.String("email", emailTo),
+		slog.String("resp status", resp.GetStatus()),
+	)
+
+	return nil
+}
+
+// SendLoginCodeEmail can take few seconds to complete.
+func (c *Client) SendLoginCodeEmail(ctx context.Context, emailTo, code string, expiresAt time.Time) error {
+	resp, err := c.client.SendNotification(ctx, &notifications.NotificationRequest{
+		Notification: &notifications.NotificationRequest_LoginCode_{
+			LoginCode: &notifications.NotificationRequest_LoginCode{
+				Email:     emailTo,
+				Code:      code,
+				ExpiresAt: expiresAt.Unix(),
+			}},
+	})
+	if err != nil {
+		return err
+	}
+
+	c.l.Debug("Email sent",
+		slog.String("method", "SendLoginCodeEmail"),
+		slog.String("email", emailTo),
+		slog.String("resp status", resp.GetStatus()),
+	)
+
+	return nil
+}
+
+// SendAccountLockedEmail can take few seconds to complete.
+func (c *Client) SendAccountLockedEmail(ctx context.Context, emailTo, ip string, lockedUntil time.Time) error {
+	resp, err := c.client.SendNotification(ctx, &notifications.NotificationRequest{
+		Notification: &notifications.NotificationRequest_AccountLocked_{
+			AccountLocked: &notifications.NotificationRequest_AccountLocked{
+				Email:       emailTo,
+				Ip:          ip,
+				LockedUntil: lockedUntil.Unix(),
+			}},
+	})
+	if err != nil {
+		return err
+	}
+
+	c.l.Debug("Email sent",
+		slog.String("method", "SendAccountLockedEmail"),
+		slog.String("email", emailTo),
+		slog.String("resp status", resp.GetStatus()),
+	)
+
+	return nil
+}
+
+// SendDataExportEmail can take few seconds to complete.
+func (c *Client) SendDataExportEmail(ctx context.Context, emailTo, exportID, code string, expiresAt time.Time) error {
+	resp, err := c.client.SendNotification(ctx, &notifications.NotificationRequest{
+		Notification: &notifications.NotificationRequest_DataExport_{
+			DataExport: &notifications.NotificationRequest_DataExport{
+				Email:     emailTo,
+				ExportId:  exportID,
+				Code:      code,
+				ExpiresAt: expiresAt.Unix(),
+			}},
+	})
+	if err != nil {
+		return err
+	}
+
+	c.l.Debug("Email sent",
+		slog.String("method", "SendDataExportEmail"),
+		slog.String("email", emailTo),
+		slog.String("resp status", resp.GetStatus()),
+	)
+
+	return nil
+}
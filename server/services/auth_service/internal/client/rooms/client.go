@@ -0,0 +1,78 @@
+package rooms
+
+import (
+	"auth_service/internal/config"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"log/slog"
+	"os"
+
+	"github.com/zumosik/grpc_chat_protos/go/rooms"
+	"google.golang.org/grpc"
+)
+
+// tokenMetadataKey is metadata key rooms service reads token of the user from
+const tokenMetadataKey = "token"
+
+type Client struct {
+	l      *slog.Logger
+	client rooms.RoomServiceClient
+}
+
+func Connect(log *slog.Logger, addr string, certCfg *config.CertsConfig) (*Client, error) {
+
+	pemServerCA, err := os.ReadFile(certCfg.CaPath)
+	if err != nil {
+		log.Error("failed to load CA cert", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(pemServerCA) {
+		log.Error("failed to append CA cert")
+		return nil, err
+	}
+
+	clientCert, err := tls.LoadX509KeyPair(certCfg.CertPath, certCfg.KeyPath)
+	if err != nil {
+		log.Error("failed to load client cert", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      certPool,
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := rooms.NewRoomServiceClient(conn)
+
+	c := &Client{
+		client: client,
+		l:      log,
+	}
+
+	log.Info("Connected to rooms service", slog.String("address", addr))
+
+	return c, nil
+}
+
+// ExportUserData returns JSON with data of the user token belongs to (memberships and created rooms)
+func (c *Client) ExportUserData(ctx context.Context, token []byte) ([]byte, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, tokenMetadataKey, string(token))
+
+	resp, err := c.client.ExportUserData(ctx, &rooms.ExportUserDataRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetData(), nil
+}
@@ -8,11 +8,22 @@ import (
 )
 
 type Config struct {
-	Env           string        `yaml:"env" env-default:"local"`
-	Storage       StorageConfig `yaml:"storage_cfg" env-required:"true"`
-	GRPC          GRPCConfig    `yaml:"grpc" env-required:"true"`
-	Tokens        Tokens        `yaml:"tokens" env-required:"true"`
-	OtherServices OtherServices `yaml:"other_services" env-required:"true"`
+	Env               string            `yaml:"env" env-default:"local"`
+	Storage           StorageConfig     `yaml:"storage_cfg" env-required:"true"`
+	GRPC              GRPCConfig        `yaml:"grpc" env-required:"true"`
+	Tokens            Tokens            `yaml:"tokens" env-required:"true"`
+	Registration      Registration      `yaml:"registration"`
+	PasswordReset     PasswordReset     `yaml:"password_reset"`
+	EmailVerification EmailVerification `yaml:"email_verification"`
+	LoginCode         LoginCode         `yaml:"login_code"`
+	PasswordPolicy    PasswordPolicy    `yaml:"password_policy"`
+	PasswordHashing   PasswordHashing   `yaml:"password_hashing"`
+	AccountDeletion   AccountDeletion   `yaml:"account_deletion"`
+	DataExport        DataExport        `yaml:"data_export"`
+	LoginThrottle     LoginThrottle     `yaml:"login_throttle"`
+	TwoFactor         TwoFactor         `yaml:"two_factor"`
+	OIDC              OIDC              `yaml:"oidc"`
+	OtherServices     OtherServices     `yaml:"other_services" env-required:"true"`
 }
 
 type GRPCConfig struct {
@@ -31,14 +42,161 @@ type StorageConfig struct {
 }
 
 type Tokens struct {
-	TokenSecret string        `yaml:"token_secret" env-required:"true"`
-	TokenTTL    time.Duration `yaml:"token_ttl" env-default:"1h"`
+	// KeysDir is directory with signing keys (see token.Keyring), keys are reloaded on SIGHUP.
+	// If it is empty single key from fields below is used.
+	KeysDir string `yaml:"keys_dir"`
+
+	// SigningAlg is one of HS256, RS256 or EdDSA.
+	// Tokens signed with RS256 or EdDSA can be verified by other services locally.
+	SigningAlg     string `yaml:"signing_alg" env-default:"HS256"`
+	KeyID          string `yaml:"key_id" env-default:"default"`
+	TokenSecret    string `yaml:"token_secret"`     // HS256 only
+	PrivateKeyPath string `yaml:"private_key_path"` // RS256 and EdDSA only, PEM encoded
+
+	TokenTTL time.Duration `yaml:"token_ttl" env-default:"1h"`
+
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env-default:"720h"`
+}
+
+// Registration modes
+const (
+	RegistrationOpen            = "open"
+	RegistrationInviteOnly      = "invite_only"
+	RegistrationDomainAllowlist = "domain_allowlist" // users with email from allowed domains or with invitation
+)
+
+// Registration configures who can sign up (with CreateUser or first oidc login)
+type Registration struct {
+	Mode           string   `yaml:"mode" env-default:"open"`
+	AllowedDomains []string `yaml:"allowed_domains"` // for domain_allowlist mode
+
+	// UserInvites allows not only admins to mint invitations, but with at most UserMaxUses uses
+	UserInvites bool `yaml:"user_invites" env-default:"true"`
+	UserMaxUses int  `yaml:"user_max_uses" env-default:"5"`
+
+	InvitationTTL    time.Duration `yaml:"invitation_ttl" env-default:"168h"` // if expiration isn't set
+	MaxInvitationTTL time.Duration `yaml:"max_invitation_ttl" env-default:"720h"`
+}
+
+type PasswordReset struct {
+	CodeTTL     time.Duration `yaml:"code_ttl" env-default:"15m"`
+	MaxAttempts int           `yaml:"max_attempts" env-default:"5"`
+	// ResendCooldown is minimal time between two codes sent to the same user
+	ResendCooldown time.Duration `yaml:"resend_cooldown" env-default:"1m"`
+}
+
+type EmailVerification struct {
+	CodeTTL     time.Duration `yaml:"code_ttl" env-default:"24h"`
+	MaxAttempts int           `yaml:"max_attempts" env-default:"5"`
+	// ResendCooldown is minimal time between two codes sent to the same user
+	ResendCooldown time.Duration `yaml:"resend_cooldown" env-default:"1m"`
+}
+
+// LoginCode configures passwordless login with codes sent to email
+type LoginCode struct {
+	CodeTTL     time.Duration `yaml:"code_ttl" env-default:"10m"`
+	MaxAttempts int           `yaml:"max_attempts" env-default:"5"`
+	// ResendCooldown is minimal time between two codes sent to the same user
+	ResendCooldown time.Duration `yaml:"resend_cooldown" env-default:"1m"`
+}
+
+type PasswordPolicy struct {
+	MinLength int `yaml:"min_length" env-default:"8"`
+	MaxLength int `yaml:"max_length" env-default:"72"` // in bytes, bcrypt uses only first 72 bytes
+	// MinCharClasses is number of classes (lowercase, uppercase, digits, other) password must use
+	MinCharClasses int `yaml:"min_char_classes" env-default:"2"`
+	// CommonPasswordsPath is file with passwords that can't be used (one per line),
+	// they are added to built-in list of common passwords
+	CommonPasswordsPath string `yaml:"common_passwords_path"`
+}
+
+// PasswordHashing configures hashing of new passwords,
+// hashes with other algorithm or parameters are replaced on next login
+type PasswordHashing struct {
+	Algorithm string `yaml:"algorithm" env-default:"argon2id"` // argon2id or bcrypt
+
+	Argon2id struct {
+		Time       uint32 `yaml:"time" env-default:"3"`
+		Memory     uint32 `yaml:"memory" env-default:"65536"` // in KiB
+		Threads    uint8  `yaml:"threads" env-default:"4"`
+		KeyLength  uint32 `yaml:"key_length" env-default:"32"`
+		SaltLength uint32 `yaml:"salt_length" env-default:"16"`
+	} `yaml:"argon2id"`
+
+	BcryptCost int `yaml:"bcrypt_cost" env-default:"10"`
+}
+
+type AccountDeletion struct {
+	// GracePeriod is time deleted account can be restored
+	GracePeriod   time.Duration `yaml:"grace_period" env-default:"720h"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env-default:"1h"`
+}
+
+// DataExport configures exports of personal data of users (ExportMyData)
+type DataExport struct {
+	Dir string `yaml:"dir" env-default:"./data_exports"` // ZIP files are stored here until they expire
+	// Cooldown is minimal time between two exports of the same user
+	Cooldown time.Duration `yaml:"cooldown" env-default:"24h"`
+	// Timeout is time export job has to collect data from all services
+	Timeout       time.Duration `yaml:"timeout" env-default:"10m"`
+	TTL           time.Duration `yaml:"ttl" env-default:"168h"` // time export can be downloaded
+	MaxAttempts   int           `yaml:"max_attempts" env-default:"5"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env-default:"1h"`
+}
+
+// LoginThrottle configures limits for failed logins, they are counted per account and per client ip.
+type LoginThrottle struct {
+	// Window is time after which failures are forgotten
+	Window time.Duration `yaml:"window" env-default:"1h"`
+
+	// After BackoffAfter (IPBackoffAfter for ip) failures every next attempt
+	// is allowed only after BaseDelay * 2^n, but not more than MaxDelay
+	BackoffAfter   int           `yaml:"backoff_after" env-default:"3"`
+	IPBackoffAfter int           `yaml:"ip_backoff_after" env-default:"20"`
+	BaseDelay      time.Duration `yaml:"base_delay" env-default:"1s"`
+	MaxDelay       time.Duration `yaml:"max_delay" env-default:"5m"`
+
+	// After LockoutAfter failures account is locked for LockoutDuration and user gets email
+	LockoutAfter    int           `yaml:"lockout_after" env-default:"10"`
+	LockoutDuration time.Duration `yaml:"lockout_duration" env-default:"30m"`
+
+	// PurgeInterval is how often rows with failures outside of Window are deleted
+	PurgeInterval time.Duration `yaml:"purge_interval" env-default:"10m"`
+}
+
+type TwoFactor struct {
+	Issuer        string        `yaml:"issuer" env-default:"chat_grpc"` // shown in authenticator app
+	ChallengeTTL  time.Duration `yaml:"challenge_ttl" env-default:"5m"`
+	MaxAttempts   int           `yaml:"max_attempts" env-default:"5"` // per login challenge
+	RecoveryCodes int           `yaml:"recovery_codes" env-default:"10"`
+}
+
+type OIDC struct {
+	StateTTL  time.Duration  `yaml:"state_ttl" env-default:"10m"` // time user has to login with provider
+	Providers []OIDCProvider `yaml:"providers"`
+}
+
+type OIDCProvider struct {
+	Name         string   `yaml:"name"` // used in rpc requests
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"` // default is openid, email, profile
+
+	// LinkByEmail allows to login to existing account with the same verified email,
+	// enable it only for providers that are trusted to verify emails (e.g. corporate IdP)
+	LinkByEmail bool `yaml:"link_by_email"`
 }
 
 type OtherServices struct {
 	NotificationServiceURL string `yaml:"notification_service_url" env-required:"true"`
+	RoomsServiceURL        string `yaml:"rooms_service_url" env-required:"true"`
+	ChatServiceURL         string `yaml:"chat_service_url" env-required:"true"`
 
 	NotificationsCert CertsConfig `yaml:"notifications_cert" env-required:"true"`
+	RoomsCert         CertsConfig `yaml:"rooms_cert" env-required:"true"`
+	ChatCert          CertsConfig `yaml:"chat_cert" env-required:"true"`
 }
 
 func MustLoad() *Config {
@@ -0,0 +1,51 @@
+package api_key
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Prefix is used to tell api keys from jwt tokens, other services check such keys only with auth service
+const Prefix = "cgk_"
+
+const (
+	keyBytes = 32
+
+	// displayLength is length of the part of the key that is stored as is and shown to user
+	displayLength = len(Prefix) + 8
+)
+
+// Generate creates new random api key and returns it with its hash.
+// Key must be given to the user once, only hash is saved in storage.
+func Generate() (key string, hash string, err error) {
+	b := make([]byte, keyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	key = Prefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return key, Hash(key), nil
+}
+
+// Hash returns hash of the key that is used to find it in storage
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports if token looks like api key and not like jwt
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+// DisplayPrefix returns beginning of the key that can be shown to user to recognize it
+func DisplayPrefix(key string) string {
+	if len(key) < displayLength {
+		return key
+	}
+	return key[:displayLength]
+}
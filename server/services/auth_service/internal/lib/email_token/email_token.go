@@ -1,16 +1,31 @@
 package email_token
 
-import "math/rand"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+)
 
-func GetRndEmailToken(length int) string {
-	// create 6 digit string
+// GetRndEmailToken returns random code of digits with given length
+func GetRndEmailToken(length int) (string, error) {
+	const chars = "0123456789"
+	max := big.NewInt(int64(len(chars)))
 
-	chars := "0123456789"
-	var result string
-
-	for i := 0; i < length; i++ {
-		result += string(chars[rand.Intn(len(chars))])
+	result := make([]byte, length)
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = chars[n.Int64()]
 	}
 
-	return result
+	return string(result), nil
+}
+
+// Hash returns hash of the code, codes that give access to account are stored only as hash
+func Hash(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
 }
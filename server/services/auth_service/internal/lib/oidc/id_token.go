@@ -0,0 +1,327 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// minRefreshInterval limits how often keys are fetched from provider,
+// so tokens with random kid can't be used to flood it
+const minRefreshInterval = time.Minute
+
+// leeway is allowed clock difference between us and provider
+const leeway = time.Minute
+
+// IDToken is verified id_token of the user
+type IDToken struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     boolean  `json:"email_verified"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+}
+
+// Valid is called by jwt parser, only expiration is checked here,
+// issuer, audience and nonce are checked in VerifyIDToken
+func (t *IDToken) Valid() error {
+	now := time.Now()
+	if t.ExpiresAt == 0 || now.After(time.Unix(t.ExpiresAt, 0).Add(leeway)) {
+		return errors.New("id_token is expired")
+	}
+	if t.IssuedAt != 0 && now.Add(leeway).Before(time.Unix(t.IssuedAt, 0)) {
+		return errors.New("id_token is issued in the future")
+	}
+	return nil
+}
+
+// audience can be string or array of strings in json
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		*a = audience{s}
+		return nil
+	}
+	var arr []string
+	if err := json.Unmarshal(b, &arr); err != nil {
+		return err
+	}
+	*a = arr
+	return nil
+}
+
+// boolean can be bool or string in json, some providers send email_verified as "true"
+type boolean bool
+
+func (b *boolean) UnmarshalJSON(data []byte) error {
+	var v bool
+	if json.Unmarshal(data, &v) == nil {
+		*b = boolean(v)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*b = boolean(s == "true")
+	return nil
+}
+
+func (a audience) contains(v string) bool {
+	for _, s := range a {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyIDToken verifies signature of id_token with provider keys (JWKS)
+// and checks that it was issued by provider for us in response to request with nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDToken, error) {
+	_, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &IDToken{}
+	parser := jwt.Parser{ValidMethods: []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}}
+	_, err = parser.ParseWithClaims(rawIDToken, t, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		k, ok := p.keys.get(ctx, kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+
+		// never let token choose the algorithm
+		if token.Method.Alg() != k.alg {
+			return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+		}
+
+		return k.key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", t.Issuer)
+	}
+	if !t.Audience.contains(p.cfg.ClientID) {
+		return nil, errors.New("id_token is issued for another client")
+	}
+	if subtle.ConstantTimeCompare([]byte(t.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("nonce mismatch")
+	}
+	if t.Subject == "" {
+		return nil, errors.New("id_token has no subject")
+	}
+
+	return t, nil
+}
+
+type publicKey struct {
+	alg string
+	key interface{}
+}
+
+// keySet is cache of provider keys, keys are fetched again when token has unknown kid
+type keySet struct {
+	uri    string
+	client *http.Client
+
+	mu   sync.RWMutex
+	keys map[string]publicKey
+
+	// refreshMu is held while keys are fetched, so only one request goes to provider
+	// and tokens signed with known keys are verified in the meantime
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
+}
+
+func newKeySet(uri string, client *http.Client) *keySet {
+	return &keySet{
+		uri:    uri,
+		client: client,
+		keys:   make(map[string]publicKey),
+	}
+}
+
+func (s *keySet) get(ctx context.Context, kid string) (publicKey, bool) {
+	s.mu.RLock()
+	k, ok := s.lookup(kid)
+	s.mu.RUnlock()
+	if ok {
+		return k, true
+	}
+
+	s.refresh(ctx)
+
+	s.mu.RLock()
+	k, ok = s.lookup(kid)
+	s.mu.RUnlock()
+
+	return k, ok
+}
+
+// lookup finds key by kid, tokens without kid are allowed only if provider has one key
+func (s *keySet) lookup(kid string) (publicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true
+		}
+	}
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// refresh replaces known keys with keys from provider,
+// keys are locked only to swap them, not while waiting for provider
+func (s *keySet) refresh(ctx context.Context) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	if time.Since(s.lastRefresh) < minRefreshInterval {
+		return
+	}
+	s.lastRefresh = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.uri, nil)
+	if err != nil {
+		return
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return
+	}
+
+	keys := make(map[string]publicKey, len(jwks.Keys))
+	for _, j := range jwks.Keys {
+		// keys for encryption are not used
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		k, err := j.parse()
+		if err != nil {
+			continue
+		}
+		keys[j.Kid] = k
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (j jwk) parse() (publicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return publicKey{}, err
+		}
+
+		alg := j.Alg
+		if alg == "" {
+			alg = "RS256"
+		}
+
+		return publicKey{alg: alg, key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var alg string
+		switch j.Crv {
+		case "P-256":
+			curve, alg = elliptic.P256(), "ES256"
+		case "P-384":
+			curve, alg = elliptic.P384(), "ES384"
+		case "P-521":
+			curve, alg = elliptic.P521(), "ES512"
+		default:
+			return publicKey{}, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(j.Y)
+		if err != nil {
+			return publicKey{}, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return publicKey{}, errors.New("invalid ec key")
+		}
+
+		return publicKey{alg: alg, key: key}, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return publicKey{}, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return publicKey{}, errors.New("invalid ed25519 key size")
+		}
+
+		return publicKey{alg: jwt.SigningMethodEdDSA.Alg(), key: ed25519.PublicKey(x)}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+}
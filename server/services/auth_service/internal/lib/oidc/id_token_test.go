@@ -0,0 +1,220 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	testClientID = "client"
+	testKid      = "key-1"
+	testNonce    = "nonce"
+)
+
+// testIssuer is stand-in provider that serves discovery document and JWKS with one RSA key
+type testIssuer struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iss := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(metadata{
+			Issuer:                iss.srv.URL,
+			AuthorizationEndpoint: iss.srv.URL + "/authorize",
+			TokenEndpoint:         iss.srv.URL + "/token",
+			JWKSURI:               iss.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string][]jwk{"keys": {{
+			Kid: testKid,
+			Kty: "RSA",
+			Alg: "RS256",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	iss.srv = httptest.NewServer(mux)
+	t.Cleanup(iss.srv.Close)
+
+	return iss
+}
+
+func (iss *testIssuer) provider() *Provider {
+	return NewProvider(Config{Issuer: iss.srv.URL, ClientID: testClientID}, iss.srv.Client())
+}
+
+// validClaims returns claims that pass verification, tests change one of them
+func (iss *testIssuer) validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            iss.srv.URL,
+		"sub":            "subject",
+		"aud":            testClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          testNonce,
+		"email":          "user@example.com",
+		"email_verified": true,
+	}
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKid
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	iss := newTestIssuer(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     *rsa.PrivateKey
+		change  func(c jwt.MapClaims)
+		nonce   string
+		wantErr bool
+	}{
+		{name: "valid", change: func(c jwt.MapClaims) {}},
+		{name: "audience array", change: func(c jwt.MapClaims) { c["aud"] = []string{"other", testClientID} }},
+		{name: "signed with another key", key: otherKey, change: func(c jwt.MapClaims) {}, wantErr: true},
+		{name: "wrong issuer", change: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, wantErr: true},
+		{name: "wrong audience", change: func(c jwt.MapClaims) { c["aud"] = "other" }, wantErr: true},
+		{name: "expired", change: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * leeway).Unix() }, wantErr: true},
+		{name: "without expiration", change: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: true},
+		{name: "wrong nonce", change: func(c jwt.MapClaims) {}, nonce: "other", wantErr: true},
+		{name: "without subject", change: func(c jwt.MapClaims) { delete(c, "sub") }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := tt.key
+			if key == nil {
+				key = iss.key
+			}
+			nonce := tt.nonce
+			if nonce == "" {
+				nonce = testNonce
+			}
+
+			claims := iss.validClaims()
+			tt.change(claims)
+
+			idToken, err := iss.provider().VerifyIDToken(context.Background(), sign(t, key, claims), nonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if idToken.Subject != "subject" {
+				t.Fatalf("unexpected subject %q", idToken.Subject)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenEmailVerified(t *testing.T) {
+	iss := newTestIssuer(t)
+
+	tests := []struct {
+		value interface{}
+		want  bool
+	}{
+		{value: true, want: true},
+		{value: false, want: false},
+		{value: "true", want: true},
+		{value: "false", want: false},
+	}
+
+	for _, tt := range tests {
+		claims := iss.validClaims()
+		claims["email_verified"] = tt.value
+
+		idToken, err := iss.provider().VerifyIDToken(context.Background(), sign(t, iss.key, claims), testNonce)
+		if err != nil {
+			t.Fatalf("email_verified %#v: unexpected error: %v", tt.value, err)
+		}
+		if bool(idToken.EmailVerified) != tt.want {
+			t.Fatalf("email_verified %#v: got %v, want %v", tt.value, idToken.EmailVerified, tt.want)
+		}
+	}
+}
+
+func TestKeySetGetDuringRefresh(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_ = json.NewEncoder(w).Encode(map[string][]jwk{"keys": {}})
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+
+	s := newKeySet(srv.URL, srv.Client())
+	s.keys[testKid] = publicKey{alg: "RS256"}
+
+	// unknown kid makes get fetch keys, provider doesn't answer until released
+	go s.get(context.Background(), "unknown")
+	<-started
+
+	// known key is returned while keys are fetched
+	done := make(chan bool)
+	go func() {
+		_, ok := s.get(context.Background(), testKid)
+		done <- ok
+	}()
+
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatal("known key not found")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("get of known key waited for provider")
+	}
+}
@@ -0,0 +1,47 @@
+package password
+
+// Hasher is one password hashing scheme
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Identifies reports if hash was created with this scheme
+	Identifies(hash string) bool
+	Verify(password, hash string) bool
+	// Outdated reports if hash was created with parameters that differ from hasher ones
+	Outdated(hash string) bool
+}
+
+// Manager hashes new passwords with current hasher and verifies
+// hashes of every known scheme, so hashing can be changed without resetting passwords
+type Manager struct {
+	current Hasher
+	known   []Hasher
+}
+
+// NewManager creates manager, hashes of others are only verified
+func NewManager(current Hasher, others ...Hasher) *Manager {
+	return &Manager{
+		current: current,
+		known:   append([]Hasher{current}, others...),
+	}
+}
+
+func (m *Manager) Hash(password string) (string, error) {
+	return m.current.Hash(password)
+}
+
+// Verify compares password with hash, needsRehash is true if password matches
+// but hash was created with other scheme or outdated parameters and should be replaced
+func (m *Manager) Verify(password, hash string) (ok, needsRehash bool) {
+	for _, h := range m.known {
+		if !h.Identifies(hash) {
+			continue
+		}
+		if !h.Verify(password, hash) {
+			return false, false
+		}
+		return true, h != m.current || h.Outdated(hash)
+	}
+
+	// unknown scheme or empty hash (accounts without password)
+	return false, false
+}
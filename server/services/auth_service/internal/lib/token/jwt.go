@@ -2,30 +2,72 @@ package token
 
 import (
 	"auth_service/internal/models"
+	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"time"
 )
 
+const kidHeader = "kid"
+
+var ErrUnknownKey = errors.New("token is signed with unknown key")
+
 type Manager struct {
-	secretKey      []byte
+	keys           *Keyring
 	expirationTime time.Duration
 }
 
-func NewManager(secretKey string, expirationTime time.Duration) *Manager {
-	return &Manager{secretKey: []byte(secretKey), expirationTime: expirationTime}
+// Claims are claims stored in JWT token,
+// Id (jti) is unique for every token, so token can be revoked.
+// User fields are here so other services can build user from locally verified token.
+type Claims struct {
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Verified bool   `json:"email_verified,omitempty"`
+	Role     string `json:"role,omitempty"`
+	// SessionID is id of the login session, token is rejected after session is revoked
+	SessionID string `json:"sid,omitempty"`
+	jwt.StandardClaims
+}
+
+// ExpiresAtTime returns expiration time of the token
+func (c *Claims) ExpiresAtTime() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
 }
 
-// CreateToken creates a new JWT token for the user
-func (ts *Manager) CreateToken(u *models.User) ([]byte, error) {
-	expirationTime := time.Now().Add(ts.expirationTime)
+// IssuedAtTime returns time when the token was created
+func (c *Claims) IssuedAtTime() time.Time {
+	return time.Unix(c.IssuedAt, 0)
+}
 
-	claims := &jwt.StandardClaims{
-		Subject:   u.ID,
-		ExpiresAt: expirationTime.Unix(),
+func NewManager(keys *Keyring, expirationTime time.Duration) *Manager {
+	return &Manager{keys: keys, expirationTime: expirationTime}
+}
+
+// CreateToken creates a new JWT token for the user in the session
+func (ts *Manager) CreateToken(u *models.User, sessionID string) ([]byte, error) {
+	now := time.Now()
+
+	claims := &Claims{
+		Username:  u.Username,
+		Email:     u.Email,
+		Verified:  u.ConfirmedEmail,
+		Role:      u.Role,
+		SessionID: sessionID,
+		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.New().String(),
+			Subject:   u.ID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ts.expirationTime).Unix(),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(ts.secretKey)
+	key := ts.keys.Active()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header[kidHeader] = key.ID
+	tokenString, err := token.SignedString(key.signKey)
 
 	if err != nil {
 		return nil, err
@@ -34,21 +76,52 @@ func (ts *Manager) CreateToken(u *models.User) ([]byte, error) {
 	return []byte(tokenString), nil
 }
 
-// ParseToken parses the JWT token and returns the user ID
-func (ts *Manager) ParseToken(tokenStrBytes []byte) (string, error) {
-	claims := &jwt.StandardClaims{}
+// ParseToken parses the JWT token and returns its claims
+func (ts *Manager) ParseToken(tokenStrBytes []byte) (*Claims, error) {
+	claims := &Claims{}
 
-	token, err := jwt.ParseWithClaims(string(tokenStrBytes), claims, func(token *jwt.Token) (interface{}, error) {
-		return ts.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(string(tokenStrBytes), claims, ts.keyFunc)
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return "", jwt.ErrSignatureInvalid
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	return claims, nil
+}
+
+// PublicKeys returns public keys that can be used to verify tokens outside of auth service,
+// verify-only keys are included until they retire
+func (ts *Manager) PublicKeys() []JWK {
+	keys := ts.keys.Keys()
+
+	res := make([]JWK, 0, len(keys))
+	for _, k := range keys {
+		if jwk, ok := k.JWK(); ok {
+			res = append(res, jwk)
+		}
+	}
+
+	return res
+}
+
+// keyFunc selects key using "kid" header,
+// tokens without kid (created before kid was added) are verified with active key
+func (ts *Manager) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := ts.keys.Active()
+	if kid, ok := token.Header[kidHeader].(string); ok {
+		if key, ok = ts.keys.Get(kid); !ok {
+			return nil, ErrUnknownKey
+		}
+	}
+
+	// never let token choose the algorithm
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
 	}
 
-	return claims.Subject, nil
+	return key.verifyKey, nil
 }
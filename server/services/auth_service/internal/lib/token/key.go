@@ -0,0 +1,123 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Key is a key used to sign and verify tokens, ID is written to "kid" header of the token
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// JWK is public part of the key in JWK format (RFC 7517),
+// only fields needed for RSA and Ed25519 keys are present
+type JWK struct {
+	Kid string
+	Kty string
+	Alg string
+	Use string
+	Crv string // OKP only
+	X   string // OKP only
+	N   string // RSA only
+	E   string // RSA only
+}
+
+// NewHMACKey creates symmetric key, tokens signed with it can be verified only by auth service
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// LoadKey loads private key from PEM file, alg must be AlgRS256 or AlgEdDSA
+func LoadKey(id, alg, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKey(id, alg, data)
+}
+
+// ParseKey parses PEM encoded private key, alg must be AlgRS256 or AlgEdDSA
+func ParseKey(id, alg string, pemData []byte) (*Key, error) {
+	switch alg {
+	case AlgRS256:
+		k, err := jwt.ParseRSAPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Key{
+			ID:        id,
+			Method:    jwt.SigningMethodRS256,
+			signKey:   k,
+			verifyKey: &k.PublicKey,
+		}, nil
+	case AlgEdDSA:
+		k, err := jwt.ParseEdPrivateKeyFromPEM(pemData)
+		if err != nil {
+			return nil, err
+		}
+
+		edKey, ok := k.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("key %s is not ed25519 private key", id)
+		}
+
+		return &Key{
+			ID:        id,
+			Method:    jwt.SigningMethodEdDSA,
+			signKey:   edKey,
+			verifyKey: edKey.Public(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+}
+
+// JWK returns public part of the key,
+// false is returned for symmetric keys as they must never be published
+func (k *Key) JWK() (JWK, bool) {
+	jwk := JWK{
+		Kid: k.ID,
+		Alg: k.Method.Alg(),
+		Use: "sig",
+	}
+
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
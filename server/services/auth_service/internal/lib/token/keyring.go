@@ -0,0 +1,284 @@
+package token
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Files in keys directory:
+//   - <kid>.pem    - PEM encoded RSA or Ed25519 private key
+//   - <kid>.secret - HS256 secret
+//   - active       - kid of the key used to sign new tokens
+//   - <kid>.retired - time when key stopped being active, written by keyring
+//     so retention isn't started again after restart
+const (
+	activeFileName = "active"
+	pemExt         = ".pem"
+	secretExt      = ".secret"
+	retiredExt     = ".retired"
+)
+
+// Keyring holds one active key used to sign new tokens and verify-only keys.
+// Key stops being used for verification (retires) once every token signed with it
+// has expired, that is retention after it stopped being active.
+// Key which file was removed is still kept in memory until it retires.
+type Keyring struct {
+	dir       string
+	retention time.Duration
+
+	mu     sync.RWMutex
+	active *Key
+	keys   map[string]*ringKey
+}
+
+type ringKey struct {
+	*Key
+	deactivatedAt time.Time // zero for active key
+}
+
+// NewKeyring creates keyring with single key that can't be rotated
+func NewKeyring(key *Key) *Keyring {
+	return &Keyring{
+		active: key,
+		keys:   map[string]*ringKey{key.ID: {Key: key}},
+	}
+}
+
+// LoadKeyring loads keys from dir, retention must be at least lifetime of tokens
+func LoadKeyring(dir string, retention time.Duration) (*Keyring, error) {
+	kr := &Keyring{
+		dir:       dir,
+		retention: retention,
+		keys:      make(map[string]*ringKey),
+	}
+
+	if err := kr.Reload(); err != nil {
+		return nil, err
+	}
+
+	return kr, nil
+}
+
+// Reload reads keys directory again, if it fails keyring isn't changed.
+// Keys that stopped being active are kept for verification until they retire,
+// keys that are already retired are removed.
+func (kr *Keyring) Reload() error {
+	if kr.dir == "" {
+		return errors.New("keyring isn't loaded from directory")
+	}
+
+	loaded, activeID, deactivated, err := readKeysDir(kr.dir)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	keys := make(map[string]*ringKey, len(loaded))
+	for id, k := range loaded {
+		rk := &ringKey{Key: k}
+
+		if id == activeID {
+			// key can be made active again
+			if _, ok := deactivated[id]; ok {
+				err = os.Remove(filepath.Join(kr.dir, id+retiredExt))
+				if err != nil {
+					return fmt.Errorf("cant remove retirement time of key %s: %w", id, err)
+				}
+			}
+		} else {
+			rk.deactivatedAt = kr.deactivatedAt(id, deactivated, now)
+
+			// time is saved, so retention doesn't start again after restart
+			if _, ok := deactivated[id]; !ok {
+				err = writeDeactivatedAt(kr.dir, id, rk.deactivatedAt)
+				if err != nil {
+					return err
+				}
+			}
+
+			if kr.isRetired(rk, now) {
+				continue
+			}
+		}
+
+		keys[id] = rk
+	}
+
+	// keys which files were removed can still have unexpired tokens
+	for id, old := range kr.keys {
+		if _, ok := keys[id]; ok || id == activeID {
+			continue
+		}
+
+		rk := &ringKey{Key: old.Key, deactivatedAt: kr.deactivatedAt(id, deactivated, now)}
+		if !kr.isRetired(rk, now) {
+			keys[id] = rk
+		}
+	}
+
+	kr.keys = keys
+	kr.active = keys[activeID].Key
+
+	return nil
+}
+
+// Active returns key used to sign new tokens
+func (kr *Keyring) Active() *Key {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	return kr.active
+}
+
+// Get returns key by its id, retired keys aren't returned
+func (kr *Keyring) Get(id string) (*Key, bool) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	rk, ok := kr.keys[id]
+	if !ok || kr.isRetired(rk, time.Now()) {
+		return nil, false
+	}
+
+	return rk.Key, true
+}
+
+// Keys returns all keys that can still be used for verification
+func (kr *Keyring) Keys() []*Key {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	now := time.Now()
+	res := make([]*Key, 0, len(kr.keys))
+	for _, rk := range kr.keys {
+		if !kr.isRetired(rk, now) {
+			res = append(res, rk.Key)
+		}
+	}
+
+	return res
+}
+
+func (kr *Keyring) isRetired(rk *ringKey, now time.Time) bool {
+	return !rk.deactivatedAt.IsZero() && now.After(rk.deactivatedAt.Add(kr.retention))
+}
+
+// deactivatedAt returns time when key stopped being active: saved time, time known from
+// previous load or now for keys that were active or seen for the first time
+// (they may still have unexpired tokens)
+func (kr *Keyring) deactivatedAt(id string, saved map[string]time.Time, now time.Time) time.Time {
+	if t, ok := saved[id]; ok {
+		return t
+	}
+	if old, ok := kr.keys[id]; ok && !old.deactivatedAt.IsZero() {
+		return old.deactivatedAt
+	}
+	return now
+}
+
+func writeDeactivatedAt(dir, id string, t time.Time) error {
+	err := os.WriteFile(filepath.Join(dir, id+retiredExt), []byte(t.UTC().Format(time.RFC3339)), 0o600)
+	if err != nil {
+		return fmt.Errorf("cant save retirement time of key %s: %w", id, err)
+	}
+	return nil
+}
+
+// readKeysDir returns keys, id of active key and saved times when keys stopped being active
+func readKeysDir(dir string) (map[string]*Key, string, map[string]time.Time, error) {
+	activeID, err := os.ReadFile(filepath.Join(dir, activeFileName))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("cant read active key id: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	keys := make(map[string]*Key)
+	deactivated := make(map[string]time.Time)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		name := e.Name()
+		ext := filepath.Ext(name)
+		id := strings.TrimSuffix(name, ext)
+		if ext != pemExt && ext != secretExt && ext != retiredExt {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		if ext == retiredExt {
+			t, err := time.Parse(time.RFC3339, string(bytes.TrimSpace(data)))
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("cant parse retirement time %s: %w", name, err)
+			}
+			deactivated[id] = t
+			continue
+		}
+
+		var k *Key
+		if ext == secretExt {
+			k = NewHMACKey(id, bytes.TrimSpace(data))
+		} else {
+			k, err = parsePrivateKey(id, data)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("cant parse key %s: %w", name, err)
+			}
+		}
+
+		keys[id] = k
+	}
+
+	id := strings.TrimSpace(string(activeID))
+	if _, ok := keys[id]; !ok {
+		return nil, "", nil, fmt.Errorf("active key %q not found in %s", id, dir)
+	}
+
+	return keys, id, deactivated, nil
+}
+
+// parsePrivateKey parses PEM encoded private key and detects its algorithm
+func parsePrivateKey(id string, pemData []byte) (*Key, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	}
+
+	switch parsed.(type) {
+	case *rsa.PrivateKey:
+		return ParseKey(id, AlgRS256, pemData)
+	case ed25519.PrivateKey:
+		return ParseKey(id, AlgEdDSA, pemData)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
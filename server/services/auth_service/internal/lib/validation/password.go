@@ -0,0 +1,117 @@
+package validation
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// PasswordPolicy checks strength of new passwords
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int // in bytes
+
+	// MinCharClasses is number of classes (lowercase, uppercase, digits, other)
+	// password must contain characters from
+	MinCharClasses int
+
+	common map[string]struct{}
+}
+
+// NewPasswordPolicy creates policy with embedded list of common passwords,
+// passwords from file at commonPasswordsPath (one per line, e.g. list of breached passwords)
+// are added to it if path isn't empty
+func NewPasswordPolicy(minLength, maxLength, minCharClasses int, commonPasswordsPath string) (*PasswordPolicy, error) {
+	p := &PasswordPolicy{
+		MinLength:      minLength,
+		MaxLength:      maxLength,
+		MinCharClasses: minCharClasses,
+		common:         make(map[string]struct{}),
+	}
+
+	p.addCommon(bufio.NewScanner(strings.NewReader(commonPasswords)))
+
+	if commonPasswordsPath != "" {
+		f, err := os.Open(commonPasswordsPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		sc := bufio.NewScanner(f)
+		p.addCommon(sc)
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (p *PasswordPolicy) addCommon(sc *bufio.Scanner) {
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.common[strings.ToLower(line)] = struct{}{}
+	}
+}
+
+// Validate checks password against policy, userInputs (e.g. username and email)
+// can't be used as password
+func (p *PasswordPolicy) Validate(password string, userInputs ...string) error {
+	if len(password) < p.MinLength {
+		return fmt.Errorf("must be at least %d characters long", p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("must be at most %d bytes long", p.MaxLength)
+	}
+
+	if charClasses(password) < p.MinCharClasses {
+		return fmt.Errorf("must contain at least %d of: lowercase letters, uppercase letters, digits, other characters", p.MinCharClasses)
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := p.common[lower]; ok {
+		return errors.New("is too common")
+	}
+	for _, input := range userInputs {
+		if input != "" && lower == strings.ToLower(input) {
+			return errors.New("is too similar to account info")
+		}
+	}
+
+	return nil
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
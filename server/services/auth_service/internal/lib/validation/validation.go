@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	UsernameMinLength = 3
+	UsernameMaxLength = 32
+
+	emailMaxLength      = 254 // RFC 5321
+	emailLocalMaxLength = 64
+)
+
+// Violations collects problems with fields of request,
+// they are returned to client as errdetails.BadRequest
+type Violations struct {
+	violations []*errdetails.BadRequest_FieldViolation
+}
+
+// Add adds violation of field if err isn't nil
+func (v *Violations) Add(field string, err error) {
+	if err == nil {
+		return
+	}
+	v.violations = append(v.violations, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: err.Error(),
+	})
+}
+
+// Err returns grpc status with codes.InvalidArgument and all violations as details,
+// nil is returned if there are no violations
+func (v *Violations) Err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid request")
+	withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: v.violations})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// Username checks that username has allowed length and contains only
+// letters, digits, '_', '.' and '-', first character must be letter or digit
+func Username(username string) error {
+	length := utf8.RuneCountInString(username)
+	if length < UsernameMinLength || length > UsernameMaxLength {
+		return fmt.Errorf("must be %d-%d characters long", UsernameMinLength, UsernameMaxLength)
+	}
+
+	for i, r := range username {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			continue
+		}
+		if i == 0 {
+			return errors.New("must start with letter or digit")
+		}
+		if r != '_' && r != '.' && r != '-' {
+			return errors.New("can contain only letters, digits, '_', '.' and '-'")
+		}
+	}
+
+	return nil
+}
+
+// Email checks that email is single address in RFC 5322 syntax without display name
+func Email(email string) error {
+	if email == "" {
+		return errors.New("is empty")
+	}
+	if len(email) > emailMaxLength {
+		return fmt.Errorf("must be at most %d characters long", emailMaxLength)
+	}
+
+	// display names and comments aren't allowed, so email must be the same as formatted address
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || strings.Trim((&mail.Address{Address: addr.Address}).String(), "<>") != email {
+		return errors.New("is not valid email address")
+	}
+
+	local, _, _ := strings.Cut(addr.Address, "@")
+	if len(local) > emailLocalMaxLength {
+		return errors.New("is not valid email address")
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Scopes that can be granted to api keys
+const (
+	ScopeRoomsRead  = "rooms:read"
+	ScopeRoomsWrite = "rooms:write"
+	ScopeChatRead   = "chat:read"
+	ScopeChatWrite  = "chat:write"
+)
+
+var scopes = map[string]bool{
+	ScopeRoomsRead:  true,
+	ScopeRoomsWrite: true,
+	ScopeChatRead:   true,
+	ScopeChatWrite:  true,
+}
+
+// ValidScope reports if scope is known
+func ValidScope(scope string) bool {
+	return scopes[scope]
+}
+
+// APIKey lets bots (or users) call other services without password, it is limited to scopes.
+// Only hash of the key is stored.
+type APIKey struct {
+	ID         string         `db:"id"`
+	UserID     string         `db:"user_id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	KeyHash    string         `db:"key_hash"`
+	Scopes     pq.StringArray `db:"scopes"`
+	Revoked    bool           `db:"revoked"`
+	LastUsedAt *time.Time     `db:"last_used_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
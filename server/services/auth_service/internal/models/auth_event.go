@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+)
+
+// Types of audit events
+const (
+	AuthEventLoginSucceeded  = "login_succeeded"
+	AuthEventLoginFailed     = "login_failed"
+	AuthEventLoginCodeSent   = "login_code_sent"
+	AuthEventTokenRefreshed  = "token_refreshed"
+	AuthEventPasswordChanged = "password_changed"
+	AuthEventPasswordReset   = "password_reset"
+	AuthEventEmailVerified   = "email_verified"
+	AuthEventEmailChanged    = "email_changed"
+	AuthEventAccountDeleted  = "account_deleted"
+	AuthEventAccountRestored = "account_restored"
+	AuthEventAccountPurged   = "account_purged"
+
+	// invitations for invite-only registration
+	AuthEventInvitationCreated = "invitation_created"
+	AuthEventInvitationRevoked = "invitation_revoked"
+
+	// actions of moderators and admins
+	AuthEventUserSuspended      = "user_suspended"
+	AuthEventUserUnsuspended    = "user_unsuspended"
+	AuthEventEmailForceVerified = "email_force_verified"
+	AuthEventForceLogout        = "force_logout"
+	AuthEventRoleChanged        = "role_changed"
+)
+
+// AuthEvent is record of security audit log, ActorID is user who did the action
+// (empty for anonymous clients and background jobs), TargetID is user the action is done with
+type AuthEvent struct {
+	ID        int64     `db:"id"`
+	Type      string    `db:"type"`
+	ActorID   string    `db:"actor_id"`
+	TargetID  string    `db:"target_id"`
+	IP        string    `db:"ip"`
+	UserAgent string    `db:"user_agent"`
+	Details   string    `db:"details"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (e *AuthEvent) ToAuthAuditEvent() *auth.AuditEvent {
+	return &auth.AuditEvent{
+		Id:        e.ID,
+		Type:      e.Type,
+		ActorId:   e.ActorID,
+		TargetId:  e.TargetID,
+		Ip:        e.IP,
+		UserAgent: e.UserAgent,
+		Details:   e.Details,
+		CreatedAt: e.CreatedAt.Unix(),
+	}
+}
+
+// AuthEventFilter is used to list audit events, events are ordered from newest
+// and listed before BeforeID (if it isn't 0), empty fields aren't used
+type AuthEventFilter struct {
+	Type     string
+	ActorID  string
+	TargetID string
+	IP       string
+	Since    time.Time
+	Until    time.Time
+	BeforeID int64
+	Limit    int
+}
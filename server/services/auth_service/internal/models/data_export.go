@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Statuses of data exports
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+// DataExport is export of personal data of the user (ZIP of JSON files).
+// Pending export must be finished before ExpiresAt, ready one can be downloaded until ExpiresAt.
+// Only hash of the download code is stored.
+type DataExport struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	Status    string    `db:"status"`
+	FilePath  string    `db:"file_path"`
+	CodeHash  string    `db:"code_hash"`
+	Attempts  int       `db:"attempts"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// IsExpired reports if export can't be downloaded (or finished) anymore
+func (e *DataExport) IsExpired() bool {
+	return time.Now().After(e.ExpiresAt)
+}
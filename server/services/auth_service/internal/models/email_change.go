@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// EmailChange is a new email of the user that isn't confirmed yet,
+// code to confirm it is sent to the new email. Only hash of the code is stored.
+type EmailChange struct {
+	UserID    string    `db:"user_id"`
+	NewEmail  string    `db:"new_email"`
+	CodeHash  string    `db:"code_hash"`
+	Attempts  int       `db:"attempts"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// EmailToken is a code sent to user's email to confirm it.
+// Only hash of the code is stored.
+type EmailToken struct {
+	ID        int       `db:"id"`
+	UserID    string    `db:"user_id"`
+	CodeHash  string    `db:"code_hash"`
+	Attempts  int       `db:"attempts"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
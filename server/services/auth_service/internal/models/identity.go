@@ -0,0 +1,25 @@
+package models
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// CanonicalUsername returns form of username that is unique and used to find users:
+// Unicode NFKC with case folding, so "Alice", "ALICE" and "Ａｌｉｃｅ" are the same username
+func CanonicalUsername(username string) string {
+	return norm.NFKC.String(cases.Fold().String(norm.NFKC.String(username)))
+}
+
+// CanonicalEmail returns form of email that is unique and used to find users.
+// Domain is case-insensitive so it is lowercased, local part is kept as is.
+// Value without '@' is lowercased entirely, the same way as in migration.
+func CanonicalEmail(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return strings.ToLower(email)
+	}
+	return email[:i+1] + strings.ToLower(email[i+1:])
+}
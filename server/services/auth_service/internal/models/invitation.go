@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+)
+
+// Invitation lets MaxUses users sign up when registration is invite-only.
+// Only hash of the code is stored.
+type Invitation struct {
+	ID        string    `db:"id"`
+	CodeHash  string    `db:"code_hash"`
+	IssuerID  *string   `db:"issuer_id"` // nil if issuer was purged
+	MaxUses   int       `db:"max_uses"`
+	Uses      int       `db:"uses"`
+	Revoked   bool      `db:"revoked"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// IsUsable reports if invitation can be used to sign up
+func (i *Invitation) IsUsable() bool {
+	return !i.Revoked && i.Uses < i.MaxUses && time.Now().Before(i.ExpiresAt)
+}
+
+// ToAuthInvitation converts the *Invitation to an *auth.Invitation, code isn't included
+func (i *Invitation) ToAuthInvitation() *auth.Invitation {
+	res := &auth.Invitation{
+		Id:        i.ID,
+		MaxUses:   int32(i.MaxUses),
+		Uses:      int32(i.Uses),
+		Revoked:   i.Revoked,
+		ExpiresAt: i.ExpiresAt.Unix(),
+		CreatedAt: i.CreatedAt.Unix(),
+	}
+	if i.IssuerID != nil {
+		res.IssuerId = *i.IssuerID
+	}
+	return res
+}
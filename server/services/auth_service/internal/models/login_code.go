@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LoginCode is a single-use code sent to user's email to login without password.
+// Only hash of the code is stored.
+type LoginCode struct {
+	ID        int       `db:"id"`
+	UserID    string    `db:"user_id"`
+	CodeHash  string    `db:"code_hash"`
+	Attempts  int       `db:"attempts"`
+	Used      bool      `db:"used"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
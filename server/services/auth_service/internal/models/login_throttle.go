@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LoginThrottle counts failed logins for one key (account or client ip)
+type LoginThrottle struct {
+	Key           string     `db:"key"`
+	Failures      int        `db:"failures"`
+	LastFailureAt time.Time  `db:"last_failure_at"`
+	BlockedUntil  *time.Time `db:"blocked_until"`
+}
+
+// IsBlocked reports if login attempts for the key are not allowed at the moment
+func (t *LoginThrottle) IsBlocked() bool {
+	return t.BlockedUntil != nil && time.Now().Before(*t.BlockedUntil)
+}
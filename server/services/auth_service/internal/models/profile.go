@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+)
+
+// Profile is public info about user that is shown in UI
+type Profile struct {
+	UserID          string     `db:"user_id"`
+	Username        string     `db:"username"` // from users table, can't be changed with profile
+	DisplayName     string     `db:"display_name"`
+	Bio             string     `db:"bio"`
+	AvatarRef       string     `db:"avatar_ref"`
+	TimeZone        string     `db:"time_zone"`
+	StatusText      string     `db:"status_text"`
+	StatusExpiresAt *time.Time `db:"status_expires_at"` // nil means status doesn't expire
+}
+
+// ToAuthProfile converts the *Profile to an *auth.Profile, expired status isn't included
+func (p *Profile) ToAuthProfile() *auth.Profile {
+	displayName := p.DisplayName
+	if displayName == "" {
+		displayName = p.Username
+	}
+
+	res := &auth.Profile{
+		UserId:      p.UserID,
+		Username:    p.Username,
+		DisplayName: displayName,
+		Bio:         p.Bio,
+		AvatarRef:   p.AvatarRef,
+		TimeZone:    p.TimeZone,
+	}
+
+	if p.StatusExpiresAt == nil || time.Now().Before(*p.StatusExpiresAt) {
+		res.StatusText = p.StatusText
+		if p.StatusExpiresAt != nil {
+			res.StatusExpiresAt = p.StatusExpiresAt.Unix()
+		}
+	}
+
+	return res
+}
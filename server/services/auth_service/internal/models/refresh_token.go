@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// RefreshToken is a single opaque refresh token.
+// Only hash of the token is stored, token itself is given to the client once.
+// All tokens created from the same login share FamilyID.
+type RefreshToken struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	FamilyID  string    `db:"family_id"`
+	TokenHash string    `db:"token_hash"`
+	Used      bool      `db:"used"`
+	Revoked   bool      `db:"revoked"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// IsExpired reports whether the token can't be used anymore because of its age
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
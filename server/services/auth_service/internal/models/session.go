@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Session is one login of the user (device), all refresh tokens issued for it have FamilyID equal to session ID
+type Session struct {
+	ID         string    `db:"id"`
+	UserID     string    `db:"user_id"`
+	DeviceName string    `db:"device_name"`
+	UserAgent  string    `db:"user_agent"`
+	IP         string    `db:"ip"`
+	Revoked    bool      `db:"revoked"`
+	CreatedAt  time.Time `db:"created_at"`
+	LastSeenAt time.Time `db:"last_seen_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// TOTP is second factor of the user, it is enabled only after user confirms it with first code
+type TOTP struct {
+	UserID       string    `db:"user_id"`
+	Secret       string    `db:"secret"`
+	Enabled      bool      `db:"enabled"`
+	LastUsedStep int64     `db:"last_used_step"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// LoginChallenge is given to user instead of token when second factor is required.
+// Only hash of the challenge is stored.
+type LoginChallenge struct {
+	ID            int       `db:"id"`
+	UserID        string    `db:"user_id"`
+	ChallengeHash string    `db:"challenge_hash"`
+	Attempts      int       `db:"attempts"`
+	Used          bool      `db:"used"`
+	ExpiresAt     time.Time `db:"expires_at"`
+	CreatedAt     time.Time `db:"created_at"`
+}
@@ -2,43 +2,129 @@ package models
 
 import (
 	"github.com/zumosik/grpc_chat_protos/go/auth"
-	"golang.org/x/crypto/bcrypt"
 	"time"
 )
 
+// Roles of users, every role can do everything previous one can
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleUser:      0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+// ValidRole reports if role is known
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
 type User struct {
 	ID                string    `db:"id"`
 	Username          string    `db:"username"`
+	UsernameCanonical string    `db:"username_canonical"` // see CanonicalUsername
 	Password          string    `db:"-"`
 	Email             string    `db:"email"`
+	EmailCanonical    string    `db:"email_canonical"` // see CanonicalEmail
 	ConfirmedEmail    bool      `db:"confirmed_email"`
 	EncryptedPassword []byte    `db:"encrypted_password"`
 	CreatedAt         time.Time `db:"created_at"`
+
+	// username or email is also used by older account, see identity_collisions
+	UsernameCollision bool `db:"username_collision"`
+	EmailCollision    bool `db:"email_collision"`
+
+	IsBot   bool    `db:"is_bot"`
+	OwnerID *string `db:"owner_id"` // user that created the bot
+
+	Role          string     `db:"role"`
+	SuspendedAt   *time.Time `db:"suspended_at"`
+	SuspendReason string     `db:"suspend_reason"`
+
+	DeletedAt *time.Time `db:"deleted_at"` // user is purged after grace period
+
+	InvitationID *string `db:"invitation_id"` // invitation used to sign up
+}
+
+// HasRole reports if user has role or higher one
+func (u *User) HasRole(role string) bool {
+	return roleRanks[u.Role] >= roleRanks[role]
+}
+
+// IsSuspended reports if user is suspended by moderator, suspended users can't login
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+// IsDeleted reports if user is pending deletion, such users can only be restored
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
 }
 
 // ToAuthUser converts the *User to an *auth.User
 func (u *User) ToAuthUser() *auth.User {
 	return &auth.User{
-		Id:       u.ID,
-		Username: u.Username,
-		Email:    u.Email,
-		Verified: u.ConfirmedEmail,
+		Id:        u.ID,
+		Username:  u.Username,
+		Email:     u.Email,
+		Verified:  u.ConfirmedEmail,
+		Bot:       u.IsBot,
+		Role:      u.Role,
+		Suspended: u.IsSuspended(),
 	}
 }
 
+// PasswordHasher hashes and verifies passwords (see password.Manager)
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, hash string) (ok, needsRehash bool)
+}
+
 // HashPassword hashes the password of the user and stores it in the EncryptedPassword field
-func (u *User) HashPassword() error {
-	encryptedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+func (u *User) HashPassword(h PasswordHasher) error {
+	encryptedPassword, err := h.Hash(u.Password)
 	if err != nil {
 		return err
 	}
 
-	u.EncryptedPassword = encryptedPassword
+	u.EncryptedPassword = []byte(encryptedPassword)
 
 	return nil
 }
 
-// ComparePassword compares the password of the user with the provided password
-func (u *User) ComparePassword(password string) bool {
-	return bcrypt.CompareHashAndPassword(u.EncryptedPassword, []byte(password)) == nil
+// ComparePassword compares the password of the user with the provided password,
+// needsRehash is true if password matches but hash should be updated (see password.Manager)
+func (u *User) ComparePassword(h PasswordHasher, password string) (ok, needsRehash bool) {
+	return h.Verify(password, string(u.EncryptedPassword))
+}
+
+// UserFilter is used to list users, users are ordered by id and listed after AfterID
+type UserFilter struct {
+	Query         string // prefix of username or email
+	Role          string
+	SuspendedOnly bool
+	AfterID       string
+	Limit         int
+}
+
+// UserSearch is used to search users by username and display name,
+// results are ordered by rank (best first)
+type UserSearch struct {
+	Query            string
+	IncludeSuspended bool
+	Offset           int
+	Limit            int
+}
+
+// UserSearchResult is user found with UserSearch with fields of profile shown in results
+type UserSearchResult struct {
+	User
+	DisplayName string  `db:"display_name"`
+	AvatarRef   string  `db:"avatar_ref"`
+	Rank        float64 `db:"rank"`
 }
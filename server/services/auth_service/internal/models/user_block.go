@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+)
+
+// UserBlock means that blocker doesn't want to see messages or get invites from blocked user
+type UserBlock struct {
+	BlockerID string    `db:"blocker_id"`
+	BlockedID string    `db:"blocked_id"`
+	Username  string    `db:"username"` // of blocked user, from users table
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// ToAuthBlockedUser converts the *UserBlock to an *auth.BlockedUser
+func (b *UserBlock) ToAuthBlockedUser() *auth.BlockedUser {
+	return &auth.BlockedUser{
+		UserId:    b.BlockedID,
+		Username:  b.Username,
+		CreatedAt: b.CreatedAt.Unix(),
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+)
+
+// Types of user events
+const (
+	UserEventDeleted = "user_deleted" // user was purged after deletion grace period
+)
+
+// UserEvent is event about user that other services should handle
+type UserEvent struct {
+	ID        int64     `db:"id"`
+	Type      string    `db:"type"`
+	UserID    string    `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (e *UserEvent) ToAuthUserEvent() *auth.UserEvent {
+	return &auth.UserEvent{
+		Id:        e.ID,
+		Type:      e.Type,
+		UserId:    e.UserID,
+		CreatedAt: e.CreatedAt.Unix(),
+	}
+}
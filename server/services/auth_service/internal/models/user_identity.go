@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// UserIdentity links account of the user in OpenID Connect provider to the user
+type UserIdentity struct {
+	ID        int       `db:"id"`
+	UserID    string    `db:"user_id"`
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// OIDCState is started login with OpenID Connect provider
+type OIDCState struct {
+	State        string    `db:"state"`
+	Provider     string    `db:"provider"`
+	Nonce        string    `db:"nonce"`
+	CodeVerifier string    `db:"code_verifier"`
+	UserID       *string   `db:"user_id"` // set if provider is linked to existing account
+	ExpiresAt    time.Time `db:"expires_at"`
+	CreatedAt    time.Time `db:"created_at"`
+}
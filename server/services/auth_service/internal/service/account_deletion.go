@@ -0,0 +1,109 @@
+package service
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const maxUserEventsPage = 1000
+
+var errAccountDeleted = status.Error(codes.FailedPrecondition, "account is scheduled for deletion")
+
+// checkActive returns error if user can't login or use tokens,
+// returned error is already grpc status
+func checkActive(u *models.User) error {
+	if u.IsSuspended() {
+		return errUserSuspended
+	}
+	if u.IsDeleted() {
+		return errAccountDeleted
+	}
+	return nil
+}
+
+// RestoreUser cancels deletion of the user, all sessions were revoked on deletion
+// so user is checked by email and password (failed attempts are throttled like logins)
+func (s *Service) RestoreUser(ctx context.Context, req *auth.RestoreUserRequest) (*auth.RestoreUserResponse, error) {
+	// 1. Find user by email and compare password
+	u, err := s.login(ctx, req.Email, req.Password, s.st.FindUserByEmail)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsDeleted() {
+		return nil, status.Error(codes.FailedPrecondition, "account isn't scheduled for deletion")
+	}
+
+	// 2. Restore user (and bots deleted with the user)
+	err = s.st.SetUserDeleted(ctx, u.ID, nil)
+	if err != nil {
+		s.l.Error("Cant restore user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	u.DeletedAt = nil
+	s.audit(ctx, models.AuthEventAccountRestored, u.ID, u.ID, "")
+
+	err = s.resetLoginFailures(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.RestoreUserResponse{
+		Success: true,
+		User:    u.ToAuthUser(),
+	}, nil
+}
+
+// ListUserEvents is used by other services to handle changes of users (e.g. purged users),
+// events are returned in order, services should remember id of last handled event
+func (s *Service) ListUserEvents(ctx context.Context, req *auth.ListUserEventsRequest) (*auth.ListUserEventsResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 || limit > maxUserEventsPage {
+		limit = maxUserEventsPage
+	}
+
+	events, err := s.stUserEvent.ListUserEvents(ctx, req.AfterId, limit)
+	if err != nil {
+		s.l.Error("Cant list user events", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &auth.ListUserEventsResponse{
+		Success: true,
+		Events:  make([]*auth.UserEvent, 0, len(events)),
+	}
+	for i := range events {
+		resp.Events = append(resp.Events, events[i].ToAuthUserEvent())
+	}
+
+	return resp, nil
+}
+
+// PurgeDeletedUsersLoop deletes users whose deletion grace period has ended until ctx is done,
+// user_deleted event is created for each purged user
+func (s *Service) PurgeDeletedUsersLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.AccountDeletion.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		ids, err := s.st.PurgeDeletedUsers(ctx, time.Now().Add(-s.cfg.AccountDeletion.GracePeriod))
+		if err != nil {
+			s.l.Error("Cant purge deleted users", slog.String("error", err.Error()))
+		}
+		for _, id := range ids {
+			s.l.Info("Purged deleted user", slog.String("user_id", id))
+			s.audit(ctx, models.AuthEventAccountPurged, "", id, "")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultUsersPageSize = 50
+	maxUsersPageSize     = 200
+)
+
+var errUserSuspended = status.Error(codes.PermissionDenied, "user is suspended")
+
+// AdminService is moderation api, every rpc requires token of moderator or admin.
+// Role is checked from storage, not from token, so it can't be stale.
+type AdminService struct {
+	s *Service
+
+	auth.UnimplementedAdminServiceServer
+}
+
+func RegisterAdmin(server *grpc.Server, service *Service) {
+	auth.RegisterAdminServiceServer(server, &AdminService{s: service})
+}
+
+func (a *AdminService) ListUsers(ctx context.Context, req *auth.ListUsersRequest) (*auth.ListUsersResponse, error) {
+	// 1. Check caller
+	_, err := a.caller(ctx, req.Token, models.RoleModerator)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Validate filter
+	if req.Role != "" && !models.ValidRole(req.Role) {
+		return nil, status.Error(codes.InvalidArgument, "unknown role")
+	}
+	limit := int(req.PageSize)
+	if limit <= 0 {
+		limit = defaultUsersPageSize
+	}
+	if limit > maxUsersPageSize {
+		limit = maxUsersPageSize
+	}
+
+	// 3. Find users, one more user is requested to know if there is next page
+	users, err := a.s.st.ListUsers(ctx, models.UserFilter{
+		Query:         req.Query,
+		Role:          req.Role,
+		SuspendedOnly: req.SuspendedOnly,
+		AfterID:       req.PageToken,
+		Limit:         limit + 1,
+	})
+	if err != nil {
+		a.s.l.Error("Cant list users", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &auth.ListUsersResponse{Success: true}
+	if len(users) > limit {
+		users = users[:limit]
+		resp.NextPageToken = users[limit-1].ID
+	}
+	resp.Users = make([]*auth.User, 0, len(users))
+	for i := range users {
+		resp.Users = append(resp.Users, users[i].ToAuthUser())
+	}
+
+	return resp, nil
+}
+
+func (a *AdminService) SuspendUser(ctx context.Context, req *auth.SuspendUserRequest) (*auth.SuspendUserResponse, error) {
+	// 1. Check caller and target
+	caller, err := a.caller(ctx, req.Token, models.RoleModerator)
+	if err != nil {
+		return nil, err
+	}
+	u, err := a.target(ctx, caller, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Suspend
+	now := time.Now()
+	err = a.s.st.SetUserSuspended(ctx, u.ID, &now, req.Reason)
+	if err != nil {
+		a.s.l.Error("Cant suspend user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// 3. Logout everywhere
+	err = a.s.revokeAllSessions(ctx, u.ID)
+	if err != nil {
+		a.s.l.Error("Cant revoke sessions", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	a.s.l.Info("User suspended", slog.String("user_id", u.ID), slog.String("by", caller.ID))
+	a.s.audit(ctx, models.AuthEventUserSuspended, caller.ID, u.ID, req.Reason)
+
+	return &auth.SuspendUserResponse{
+		Success: true,
+	}, nil
+}
+
+func (a *AdminService) UnsuspendUser(ctx context.Context, req *auth.UnsuspendUserRequest) (*auth.UnsuspendUserResponse, error) {
+	// 1. Check caller and target
+	caller, err := a.caller(ctx, req.Token, models.RoleModerator)
+	if err != nil {
+		return nil, err
+	}
+	u, err := a.target(ctx, caller, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Unsuspend
+	err = a.s.st.SetUserSuspended(ctx, u.ID, nil, "")
+	if err != nil {
+		a.s.l.Error("Cant unsuspend user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	a.s.l.Info("User unsuspended", slog.String("user_id", u.ID), slog.String("by", caller.ID))
+	a.s.audit(ctx, models.AuthEventUserUnsuspended, caller.ID, u.ID, "")
+
+	return &auth.UnsuspendUserResponse{
+		Success: true,
+	}, nil
+}
+
+func (a *AdminService) ForceVerifyEmail(ctx context.Context, req *auth.ForceVerifyEmailRequest) (*auth.ForceVerifyEmailResponse, error) {
+	// 1. Check caller
+	caller, err := a.caller(ctx, req.Token, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	u, err := a.target(ctx, caller, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Verify email
+	u.ConfirmedEmail = true
+	err = a.s.st.UpdateUser(ctx, u)
+	if err != nil {
+		a.s.l.Error("Cant update user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	err = a.s.stEmailToken.DeleteEmailTokens(ctx, u.ID)
+	if err != nil {
+		a.s.l.Error("Cant delete email verification code from db", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	a.s.audit(ctx, models.AuthEventEmailForceVerified, caller.ID, u.ID, u.Email)
+
+	return &auth.ForceVerifyEmailResponse{
+		Success: true,
+		User:    u.ToAuthUser(),
+	}, nil
+}
+
+func (a *AdminService) ForceLogout(ctx context.Context, req *auth.ForceLogoutRequest) (*auth.ForceLogoutResponse, error) {
+	// 1. Check caller and target
+	caller, err := a.caller(ctx, req.Token, models.RoleModerator)
+	if err != nil {
+		return nil, err
+	}
+	u, err := a.target(ctx, caller, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Logout everywhere
+	err = a.s.revokeAllSessions(ctx, u.ID)
+	if err != nil {
+		a.s.l.Error("Cant revoke sessions", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	a.s.audit(ctx, models.AuthEventForceLogout, caller.ID, u.ID, "")
+
+	return &auth.ForceLogoutResponse{
+		Success: true,
+	}, nil
+}
+
+func (a *AdminService) SetUserRole(ctx context.Context, req *auth.SetUserRoleRequest) (*auth.SetUserRoleResponse, error) {
+	// 1. Check caller and target
+	caller, err := a.caller(ctx, req.Token, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !models.ValidRole(req.Role) {
+		return nil, status.Error(codes.InvalidArgument, "unknown role")
+	}
+	u, err := a.target(ctx, caller, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Set role, it is in tokens issued after this
+	err = a.s.st.SetUserRole(ctx, u.ID, req.Role)
+	if err != nil {
+		a.s.l.Error("Cant set user role", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	u.Role = req.Role
+
+	a.s.l.Info("User role changed", slog.String("user_id", u.ID), slog.String("role", req.Role), slog.String("by", caller.ID))
+	a.s.audit(ctx, models.AuthEventRoleChanged, caller.ID, u.ID, req.Role)
+
+	return &auth.SetUserRoleResponse{
+		Success: true,
+		User:    u.ToAuthUser(),
+	}, nil
+}
+
+// caller returns user of the token if it has role (or higher one).
+// Returned error is already grpc status.
+func (a *AdminService) caller(ctx context.Context, t *auth.Token, role string) (*models.User, error) {
+	claims, err := a.s.parseToken(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := a.s.st.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		a.s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err := checkActive(u); err != nil {
+		return nil, err
+	}
+	if !u.HasRole(role) {
+		return nil, status.Error(codes.PermissionDenied, "not enough permissions")
+	}
+
+	return u, nil
+}
+
+// target returns user the action is done with, only users with lower role
+// can be moderated (admin can moderate everyone, but not own account).
+// Returned error is already grpc status.
+func (a *AdminService) target(ctx context.Context, caller *models.User, userID string) (*models.User, error) {
+	if userID == caller.ID {
+		return nil, status.Error(codes.InvalidArgument, "cant do it with yourself")
+	}
+
+	u, err := a.s.st.GetUserByID(ctx, userID)
+	if err != nil {
+		a.s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if caller.Role != models.RoleAdmin && u.HasRole(caller.Role) {
+		return nil, status.Error(codes.PermissionDenied, "not enough permissions")
+	}
+
+	return u, nil
+}
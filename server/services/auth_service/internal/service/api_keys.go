@@ -0,0 +1,255 @@
+package service
+
+import (
+	"auth_service/internal/lib/api_key"
+	"auth_service/internal/lib/token"
+	"auth_service/internal/lib/validation"
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// botEmailDomain is reserved domain (RFC 2606), bots have no real email but email column is required
+const botEmailDomain = "bots.invalid"
+
+func (s *Service) CreateBot(ctx context.Context, req *auth.CreateBotRequest) (*auth.CreateBotResponse, error) {
+	// 1. Get owner from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	var v validation.Violations
+	v.Add("username", validation.Username(req.Username))
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	// 2. Check if username already exists
+	u, err := s.st.FindUserByUsername(ctx, req.Username)
+	if err != nil {
+		s.l.Error("Cant find user by username", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u != nil {
+		return nil, status.Error(codes.AlreadyExists, "user with this username already exists")
+	}
+
+	// 3. Create bot, it has no password so it can use only api keys
+	bot := &models.User{
+		Username:          req.Username,
+		Email:             req.Username + "@" + botEmailDomain,
+		EncryptedPassword: []byte{}, // no password matches empty hash
+		CreatedAt:         time.Now(),
+		IsBot:             true,
+		OwnerID:           &claims.Subject,
+	}
+	err = s.st.CreateUser(ctx, bot)
+	if err != nil {
+		s.l.Error("Cant create bot", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.CreateBotResponse{
+		Success: true,
+		User:    bot.ToAuthUser(),
+	}, nil
+}
+
+// CreateAPIKey creates key for the caller or for bot owned by the caller,
+// key is returned only once, after that only its prefix is shown
+func (s *Service) CreateAPIKey(ctx context.Context, req *auth.CreateAPIKeyRequest) (*auth.CreateAPIKeyResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Validate scopes
+	if len(req.Scopes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !models.ValidScope(scope) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown scope %q", scope)
+		}
+	}
+
+	// 3. Find user the key is for
+	u, err := s.apiKeyUser(ctx, claims, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Create key
+	key, hash, err := api_key.Generate()
+	if err != nil {
+		s.l.Error("Cant generate api key", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	k := &models.APIKey{
+		UserID:  u.ID,
+		Name:    req.Name,
+		Prefix:  api_key.DisplayPrefix(key),
+		KeyHash: hash,
+		Scopes:  req.Scopes,
+	}
+	err = s.stAPIKey.CreateAPIKey(ctx, k)
+	if err != nil {
+		s.l.Error("Cant save api key", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	k.CreatedAt = time.Now()
+
+	return &auth.CreateAPIKeyResponse{
+		Success: true,
+		Key:     key,
+		ApiKey:  apiKeyToProto(k),
+	}, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context, req *auth.ListAPIKeysRequest) (*auth.ListAPIKeysResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Find user the keys are for
+	u, err := s.apiKeyUser(ctx, claims, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Find keys
+	keys, err := s.stAPIKey.ListAPIKeys(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant list api keys", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &auth.ListAPIKeysResponse{
+		Success: true,
+		ApiKeys: make([]*auth.ApiKey, 0, len(keys)),
+	}
+	for i := range keys {
+		resp.ApiKeys = append(resp.ApiKeys, apiKeyToProto(&keys[i]))
+	}
+
+	return resp, nil
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, req *auth.RevokeAPIKeyRequest) (*auth.RevokeAPIKeyResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Find key and check that caller can manage it
+	k, err := s.stAPIKey.GetAPIKey(ctx, req.KeyId)
+	if err != nil {
+		s.l.Error("Cant get api key", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if k == nil {
+		return nil, status.Error(codes.NotFound, "api key not found")
+	}
+
+	_, err = s.apiKeyUser(ctx, claims, k.UserID)
+	if err != nil {
+		if status.Code(err) == codes.PermissionDenied {
+			return nil, status.Error(codes.NotFound, "api key not found")
+		}
+		return nil, err
+	}
+
+	// 3. Revoke it
+	err = s.stAPIKey.RevokeAPIKey(ctx, k.ID)
+	if err != nil {
+		s.l.Error("Cant revoke api key", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.RevokeAPIKeyResponse{
+		Success: true,
+	}, nil
+}
+
+// apiKeyUser returns user whose api keys caller can manage: caller itself (empty userID) or bot owned by caller.
+// Returned error is already grpc status.
+func (s *Service) apiKeyUser(ctx context.Context, claims *token.Claims, userID string) (*models.User, error) {
+	if userID == "" {
+		userID = claims.Subject
+	}
+
+	u, err := s.st.GetUserByID(ctx, userID)
+	if err != nil {
+		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	if u.ID != claims.Subject && (u.OwnerID == nil || *u.OwnerID != claims.Subject) {
+		return nil, status.Error(codes.PermissionDenied, "user is not your bot")
+	}
+
+	return u, nil
+}
+
+// authenticateAPIKey returns user of the api key and scopes granted to it.
+// Returned error is already grpc status.
+func (s *Service) authenticateAPIKey(ctx context.Context, key string) (*models.User, []string, error) {
+	k, err := s.stAPIKey.GetAPIKeyByHash(ctx, api_key.Hash(key))
+	if err != nil {
+		s.l.Error("Cant get api key", slog.String("error", err.Error()))
+		return nil, nil, status.Error(codes.Internal, "internal error")
+	}
+	if k == nil || k.Revoked {
+		return nil, nil, status.Error(codes.Unauthenticated, "invalid api key")
+	}
+
+	u, err := s.st.GetUserByID(ctx, k.UserID)
+	if err != nil {
+		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err := checkActive(u); err != nil {
+		return nil, nil, err
+	}
+
+	err = s.stAPIKey.TouchAPIKey(ctx, k.ID)
+	if err != nil {
+		// key is valid anyway, so request shouldn't fail
+		s.l.Error("Cant update api key last usage", slog.String("error", err.Error()))
+	}
+
+	return u, k.Scopes, nil
+}
+
+func apiKeyToProto(k *models.APIKey) *auth.ApiKey {
+	var lastUsedAt int64
+	if k.LastUsedAt != nil {
+		lastUsedAt = k.LastUsedAt.Unix()
+	}
+
+	return &auth.ApiKey{
+		Id:         k.ID,
+		UserId:     k.UserID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreatedAt:  k.CreatedAt.Unix(),
+		LastUsedAt: lastUsedAt,
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultAuditEventsPageSize = 50
+	maxAuditEventsPageSize     = 500
+)
+
+// audit appends event to audit log, ip and user agent of the client are taken from ctx.
+// Failed write doesn't fail the action, event is logged instead so it isn't lost.
+func (s *Service) audit(ctx context.Context, eventType, actorID, targetID, details string) {
+	_, userAgent := clientDevice(ctx)
+	event := &models.AuthEvent{
+		Type:      eventType,
+		ActorID:   actorID,
+		TargetID:  targetID,
+		IP:        clientIP(ctx),
+		UserAgent: userAgent,
+		Details:   details,
+	}
+
+	err := s.stAuthEvent.CreateAuthEvent(ctx, event)
+	if err != nil {
+		s.l.Error("Cant save audit event",
+			slog.String("error", err.Error()),
+			slog.String("type", event.Type),
+			slog.String("actor_id", event.ActorID),
+			slog.String("target_id", event.TargetID),
+			slog.String("ip", event.IP),
+			slog.String("user_agent", event.UserAgent),
+			slog.String("details", event.Details),
+		)
+	}
+}
+
+// ListAuditEvents returns audit events from newest, only admins can read audit log
+func (a *AdminService) ListAuditEvents(ctx context.Context, req *auth.ListAuditEventsRequest) (*auth.ListAuditEventsResponse, error) {
+	// 1. Check caller
+	_, err := a.caller(ctx, req.Token, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Validate filter
+	limit := int(req.PageSize)
+	if limit <= 0 {
+		limit = defaultAuditEventsPageSize
+	}
+	if limit > maxAuditEventsPageSize {
+		limit = maxAuditEventsPageSize
+	}
+	filter := models.AuthEventFilter{
+		Type:     req.Type,
+		ActorID:  req.ActorId,
+		TargetID: req.TargetId,
+		IP:       req.Ip,
+		Limit:    limit + 1,
+	}
+	if req.Since != 0 {
+		filter.Since = time.Unix(req.Since, 0)
+	}
+	if req.Until != 0 {
+		filter.Until = time.Unix(req.Until, 0)
+	}
+	if req.PageToken != "" {
+		filter.BeforeID, err = strconv.ParseInt(req.PageToken, 10, 64)
+		if err != nil || filter.BeforeID <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+
+	// 3. Find events, one more event is requested to know if there is next page
+	events, err := a.s.stAuthEvent.ListAuthEvents(ctx, filter)
+	if err != nil {
+		a.s.l.Error("Cant list audit events", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &auth.ListAuditEventsResponse{Success: true}
+	if len(events) > limit {
+		events = events[:limit]
+		resp.NextPageToken = strconv.FormatInt(events[limit-1].ID, 10)
+	}
+	resp.Events = make([]*auth.AuditEvent, 0, len(events))
+	for i := range events {
+		resp.Events = append(resp.Events, events[i].ToAuthAuditEvent())
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// metadata keys that clients can set to describe device
+const (
+	deviceNameMD = "x-device-name"
+	// userAgentMD is set by http gateways to pass browser user agent,
+	// otherwise user agent of grpc client is used
+	userAgentMD     = "x-user-agent"
+	grpcUserAgentMD = "user-agent"
+)
+
+// clientIP returns ip of the client from grpc peer info or empty string if it is unknown
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// clientDevice returns device name and user agent of the client from grpc metadata
+func clientDevice(ctx context.Context) (deviceName, userAgent string) {
+	deviceName = metadataValue(ctx, deviceNameMD)
+
+	userAgent = metadataValue(ctx, userAgentMD)
+	if userAgent == "" {
+		userAgent = metadataValue(ctx, grpcUserAgentMD)
+	}
+
+	return deviceName, userAgent
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,436 @@
+package service
+
+import (
+	"archive/zip"
+	"auth_service/internal/lib/email_token"
+	"auth_service/internal/models"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	dataExportCodeLength = 8
+	dataExportChunkSize  = 64 * 1024
+)
+
+// authExport is content of auth.json in data export, hashes of passwords and keys aren't included
+type authExport struct {
+	User struct {
+		ID             string     `json:"id"`
+		Username       string     `json:"username"`
+		Email          string     `json:"email"`
+		ConfirmedEmail bool       `json:"confirmed_email"`
+		Role           string     `json:"role"`
+		IsBot          bool       `json:"is_bot"`
+		CreatedAt      time.Time  `json:"created_at"`
+		SuspendedAt    *time.Time `json:"suspended_at,omitempty"`
+		SuspendReason  string     `json:"suspend_reason,omitempty"`
+	} `json:"user"`
+
+	Profile *exportedProfile `json:"profile,omitempty"`
+
+	Sessions []exportedSession `json:"sessions"`
+
+	APIKeys []exportedAPIKey `json:"api_keys"`
+
+	Identities []exportedIdentity `json:"identities"`
+}
+
+type exportedProfile struct {
+	DisplayName     string     `json:"display_name"`
+	Bio             string     `json:"bio"`
+	AvatarRef       string     `json:"avatar_ref"`
+	TimeZone        string     `json:"time_zone"`
+	StatusText      string     `json:"status_text"`
+	StatusExpiresAt *time.Time `json:"status_expires_at,omitempty"`
+}
+
+type exportedSession struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type exportedAPIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Revoked    bool       `json:"revoked"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type exportedIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportMyData starts export of all data of the user stored by auth, rooms and chat services.
+// Export is made in background, code to download it is sent to email of the user.
+func (s *Service) ExportMyData(ctx context.Context, req *auth.ExportMyDataRequest) (*auth.ExportMyDataResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.st.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err := checkActive(u); err != nil {
+		return nil, err
+	}
+	if u.IsBot {
+		return nil, status.Error(codes.FailedPrecondition, "bots have no email to send export to")
+	}
+
+	// 2. Check cooldown, failed exports (and pending ones that will never finish) aren't counted
+	last, err := s.stDataExport.GetLatestDataExport(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant get latest data export", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if last != nil && time.Since(last.CreatedAt) < s.cfg.DataExport.Cooldown {
+		if last.Status == models.DataExportReady || (last.Status == models.DataExportPending && !last.IsExpired()) {
+			return nil, status.Error(codes.ResourceExhausted, "data export was requested recently")
+		}
+	}
+
+	// 3. Create export and start collecting data
+	export := &models.DataExport{
+		UserID:    u.ID,
+		Status:    models.DataExportPending,
+		ExpiresAt: time.Now().Add(s.cfg.DataExport.Timeout),
+	}
+	err = s.stDataExport.CreateDataExport(ctx, export)
+	if err != nil {
+		s.l.Error("Cant create data export", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// job must not be canceled with request
+	s.exports.Add(1)
+	go func() {
+		defer s.exports.Done()
+		s.runDataExport(export, u, claims.SessionID)
+	}()
+
+	return &auth.ExportMyDataResponse{
+		Success:  true,
+		ExportId: export.ID,
+	}, nil
+}
+
+// DownloadMyData sends ZIP file of ready export in chunks, it requires code sent to email of the user
+func (s *Service) DownloadMyData(req *auth.DownloadMyDataRequest, stream auth.AuthService_DownloadMyDataServer) error {
+	ctx := stream.Context()
+
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return err
+	}
+
+	// 2. Find export of the user
+	export, err := s.stDataExport.GetDataExport(ctx, req.ExportId)
+	if err != nil {
+		s.l.Error("Cant get data export", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+	if export == nil || export.UserID != claims.Subject {
+		return status.Error(codes.NotFound, "export not found")
+	}
+	if export.Status != models.DataExportReady || export.IsExpired() || export.Attempts >= s.cfg.DataExport.MaxAttempts {
+		return status.Error(codes.FailedPrecondition, "export isn't available")
+	}
+
+	// 3. Compare codes, every wrong guess is counted
+	if subtle.ConstantTimeCompare([]byte(email_token.Hash(req.Code)), []byte(export.CodeHash)) != 1 {
+		err = s.stDataExport.IncrementDataExportAttempts(ctx, export.ID)
+		if err != nil {
+			s.l.Error("Cant increment data export attempts", slog.String("error", err.Error()))
+			return status.Error(codes.Internal, "internal error")
+		}
+		return status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	// 4. Send file
+	f, err := os.Open(export.FilePath)
+	if err != nil {
+		s.l.Error("Cant open data export file", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	buf := make([]byte, dataExportChunkSize)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&auth.DownloadMyDataResponse{Chunk: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			s.l.Error("Cant read data export file", slog.String("error", err.Error()))
+			return status.Error(codes.Internal, "internal error")
+		}
+	}
+}
+
+// PurgeDataExportsLoop deletes expired exports and their files until ctx is done
+func (s *Service) PurgeDataExportsLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.DataExport.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		exports, err := s.stDataExport.DeleteExpiredDataExports(ctx, time.Now())
+		if err != nil {
+			s.l.Error("Cant delete expired data exports", slog.String("error", err.Error()))
+		}
+		for _, export := range exports {
+			if export.FilePath == "" {
+				continue
+			}
+			if err := os.Remove(export.FilePath); err != nil && !os.IsNotExist(err) {
+				s.l.Error("Cant remove data export file", slog.String("error", err.Error()))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StopDataExports cancels running data export jobs and waits until they are marked as failed,
+// so no export is left pending after shutdown
+func (s *Service) StopDataExports() {
+	s.stopExports()
+	s.exports.Wait()
+}
+
+// runDataExport collects data of the user, writes it to ZIP file and sends download code to the user,
+// export is marked as failed if any step fails
+func (s *Service) runDataExport(export *models.DataExport, u *models.User, sessionID string) {
+	ctx, cancel := context.WithDeadline(s.exportsCtx, export.ExpiresAt)
+	defer cancel()
+
+	l := s.l.With(slog.String("export_id", export.ID), slog.String("user_id", u.ID))
+
+	err := s.buildDataExport(ctx, export, u, sessionID)
+	if err != nil {
+		l.Error("Cant export user data", slog.String("error", err.Error()))
+
+		export.Status = models.DataExportFailed
+		export.CodeHash = ""
+		if export.FilePath != "" {
+			_ = os.Remove(export.FilePath)
+			export.FilePath = ""
+		}
+		// ctx can be already done
+		if err := s.stDataExport.FinishDataExport(context.Background(), export); err != nil {
+			l.Error("Cant mark data export as failed", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	l.Info("User data exported")
+}
+
+func (s *Service) buildDataExport(ctx context.Context, export *models.DataExport, u *models.User, sessionID string) error {
+	// 1. Collect data from auth service
+	authData, err := s.collectAuthData(ctx, u)
+	if err != nil {
+		return fmt.Errorf("collect auth data: %w", err)
+	}
+
+	// 2. Collect data from other services, they get token of the user like any other client
+	token, err := s.tokenManager.CreateToken(u, sessionID)
+	if err != nil {
+		return fmt.Errorf("create token: %w", err)
+	}
+
+	roomsData, err := s.roomsService.ExportUserData(ctx, token)
+	if err != nil {
+		return fmt.Errorf("export rooms data: %w", err)
+	}
+
+	chatData, err := s.chatService.ExportUserData(ctx, token)
+	if err != nil {
+		return fmt.Errorf("export chat data: %w", err)
+	}
+
+	// 3. Write ZIP file
+	export.FilePath = filepath.Join(s.cfg.DataExport.Dir, export.ID+".zip")
+	err = writeZip(export.FilePath, map[string][]byte{
+		"auth.json":  authData,
+		"rooms.json": roomsData,
+		"chat.json":  chatData,
+	})
+	if err != nil {
+		return fmt.Errorf("write zip: %w", err)
+	}
+
+	// 4. Mark export as ready
+	code, err := email_token.GetRndEmailToken(dataExportCodeLength)
+	if err != nil {
+		return fmt.Errorf("generate code: %w", err)
+	}
+
+	export.Status = models.DataExportReady
+	export.CodeHash = email_token.Hash(code)
+	export.ExpiresAt = time.Now().Add(s.cfg.DataExport.TTL)
+	err = s.stDataExport.FinishDataExport(ctx, export)
+	if err != nil {
+		return fmt.Errorf("finish export: %w", err)
+	}
+
+	// 5. Send code
+	err = s.notificationService.SendDataExportEmail(ctx, u.Email, export.ID, code, export.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("send email (notifications service issue): %w", err)
+	}
+
+	return nil
+}
+
+// collectAuthData returns auth.json of data export
+func (s *Service) collectAuthData(ctx context.Context, u *models.User) ([]byte, error) {
+	var data authExport
+
+	data.User.ID = u.ID
+	data.User.Username = u.Username
+	data.User.Email = u.Email
+	data.User.ConfirmedEmail = u.ConfirmedEmail
+	data.User.Role = u.Role
+	data.User.IsBot = u.IsBot
+	data.User.CreatedAt = u.CreatedAt
+	data.User.SuspendedAt = u.SuspendedAt
+	data.User.SuspendReason = u.SuspendReason
+
+	profiles, err := s.stProfile.GetProfiles(ctx, []string{u.ID})
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) > 0 {
+		p := profiles[0]
+		data.Profile = &exportedProfile{
+			DisplayName:     p.DisplayName,
+			Bio:             p.Bio,
+			AvatarRef:       p.AvatarRef,
+			TimeZone:        p.TimeZone,
+			StatusText:      p.StatusText,
+			StatusExpiresAt: p.StatusExpiresAt,
+		}
+	}
+
+	sessions, err := s.stSession.ListUserSessions(ctx, u.ID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	data.Sessions = make([]exportedSession, 0, len(sessions))
+	for _, session := range sessions {
+		data.Sessions = append(data.Sessions, exportedSession{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+		})
+	}
+
+	keys, err := s.stAPIKey.ListAPIKeys(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	data.APIKeys = make([]exportedAPIKey, 0, len(keys))
+	for _, k := range keys {
+		data.APIKeys = append(data.APIKeys, exportedAPIKey{
+			ID:         k.ID,
+			Name:       k.Name,
+			Prefix:     k.Prefix,
+			Scopes:     k.Scopes,
+			Revoked:    k.Revoked,
+			LastUsedAt: k.LastUsedAt,
+			CreatedAt:  k.CreatedAt,
+		})
+	}
+
+	identities, err := s.stUserIdentity.ListUserIdentities(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	data.Identities = make([]exportedIdentity, 0, len(identities))
+	for _, identity := range identities {
+		data.Identities = append(data.Identities, exportedIdentity{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// writeZip writes files to new ZIP archive at path
+func writeZip(path string, files map[string][]byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(content); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"auth_service/internal/lib/email_token"
+	"auth_service/internal/models"
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConfirmEmailChange sets pending email (see UpdateUser) as email of the user
+func (s *Service) ConfirmEmailChange(ctx context.Context, req *auth.ConfirmEmailChangeRequest) (*auth.ConfirmEmailChangeResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Find pending email of the user
+	change, err := s.stEmailChange.GetEmailChange(ctx, claims.Subject)
+	if err != nil {
+		s.l.Error("Cant get email change", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if change == nil || time.Now().After(change.ExpiresAt) {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+	}
+	if change.Attempts >= s.cfg.EmailVerification.MaxAttempts {
+		return nil, status.Error(codes.ResourceExhausted, "too many attempts, request new code")
+	}
+
+	// 3. Compare codes, every wrong guess is counted
+	if subtle.ConstantTimeCompare([]byte(email_token.Hash(req.Code)), []byte(change.CodeHash)) != 1 {
+		err = s.stEmailChange.IncrementEmailChangeAttempts(ctx, change.UserID)
+		if err != nil {
+			s.l.Error("Cant increment email change attempts", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+	}
+
+	// 4. Check that email wasn't taken while it was pending
+	other, err := s.st.FindUserByEmail(ctx, change.NewEmail)
+	if err != nil {
+		s.l.Error("Cant find user by email", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if other != nil && other.ID != change.UserID {
+		return nil, status.Error(codes.AlreadyExists, "user with this email already exists")
+	}
+
+	// 5. Set new email
+	err = s.stEmailChange.ApplyEmailChange(ctx, change.UserID, change.NewEmail)
+	if err != nil {
+		s.l.Error("Cant apply email change", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	s.audit(ctx, models.AuthEventEmailChanged, change.UserID, change.UserID, change.NewEmail)
+
+	u, err := s.st.GetUserByID(ctx, change.UserID)
+	if err != nil {
+		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	return &auth.ConfirmEmailChangeResponse{
+		Success: true,
+		User:    u.ToAuthUser(),
+	}, nil
+}
+
+// requestEmailChange saves new email of the user as pending and sends code to it,
+// returned error is already grpc status
+func (s *Service) requestEmailChange(ctx context.Context, u *models.User, newEmail string) error {
+	code, err := email_token.GetRndEmailToken(emailVerificationCodeLength)
+	if err != nil {
+		s.l.Error("Cant generate email change code", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.stEmailChange.CreateEmailChange(ctx, &models.EmailChange{
+		UserID:    u.ID,
+		NewEmail:  newEmail,
+		CodeHash:  email_token.Hash(code),
+		ExpiresAt: time.Now().Add(s.cfg.EmailVerification.CodeTTL),
+	})
+	if err != nil {
+		s.l.Error("Cant save email change", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.notificationService.SendEmailConfirmationEmail(ctx, code, newEmail)
+	if err != nil {
+		s.l.Error("Cant use SendEmailConfirmationEmail (notifications service issue)", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"auth_service/internal/lib/email_token"
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const emailVerificationCodeLength = 6
+
+// ResendVerification sends new email confirmation code, the response is the same for unknown email,
+// confirmed email and when code was sent recently, so this rpc can't be used to check if user exists
+func (s *Service) ResendVerification(ctx context.Context, req *auth.ResendVerificationRequest) (*auth.ResendVerificationResponse, error) {
+	// 1. Find user by email
+	u, err := s.st.FindUserByEmail(ctx, req.Email)
+	if err != nil {
+		s.l.Error("Cant find user by email", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// errors after the user is found are only logged
+	resp := &auth.ResendVerificationResponse{Success: true}
+	if u == nil || u.ConfirmedEmail {
+		return resp, nil
+	}
+
+	// 2. Check cooldown, code isn't sent if it was sent recently
+	t, err := s.stEmailToken.GetEmailToken(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant get email verification code", slog.String("error", err.Error()))
+		return resp, nil
+	}
+	if t != nil && time.Since(t.CreatedAt) < s.cfg.EmailVerification.ResendCooldown {
+		return resp, nil
+	}
+
+	// 3. Create and send new code, previous code stops working (errors are logged by sendVerificationCode)
+	_ = s.sendVerificationCode(ctx, u)
+
+	return resp, nil
+}
+
+// sendVerificationCode creates new email confirmation code for user and sends it,
+// returned error is already grpc status
+func (s *Service) sendVerificationCode(ctx context.Context, u *models.User) error {
+	code, err := email_token.GetRndEmailToken(emailVerificationCodeLength)
+	if err != nil {
+		s.l.Error("Cant generate email verification code", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.stEmailToken.CreateEmailToken(ctx, u.ID, email_token.Hash(code), time.Now().Add(s.cfg.EmailVerification.CodeTTL))
+	if err != nil {
+		s.l.Error("Cant save to storage token for email confirm", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.notificationService.SendEmailConfirmationEmail(ctx, code, u.Email)
+	if err != nil {
+		s.l.Error("Cant use SendEmailConfirmationEmail (notifications service issue)", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/lib/refresh_token"
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errInvalidInvitation = status.Error(codes.PermissionDenied, "invalid or expired invitation")
+
+// checkRegistration checks if user with email can sign up in configured registration mode.
+// Invitation can be used in every mode, then hash of its code is returned and the invitation
+// must be used when user is created. Returned error is already grpc status.
+func (s *Service) checkRegistration(ctx context.Context, email, inviteCode string) (string, error) {
+	cfg := s.cfg.Registration
+
+	// 1. Invitation
+	if inviteCode != "" {
+		hash := refresh_token.Hash(inviteCode)
+		inv, err := s.stInvitation.GetInvitationByHash(ctx, hash)
+		if err != nil {
+			s.l.Error("Cant get invitation", slog.String("error", err.Error()))
+			return "", status.Error(codes.Internal, "internal error")
+		}
+		if inv == nil || !inv.IsUsable() {
+			return "", errInvalidInvitation
+		}
+		return hash, nil
+	}
+
+	// 2. Without invitation
+	switch cfg.Mode {
+	case config.RegistrationOpen:
+		return "", nil
+	case config.RegistrationDomainAllowlist:
+		domain := strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+		if slices.ContainsFunc(cfg.AllowedDomains, func(d string) bool { return strings.ToLower(d) == domain }) {
+			return "", nil
+		}
+		return "", status.Error(codes.PermissionDenied, "registration requires invitation or email from allowed domain")
+	default:
+		return "", status.Error(codes.PermissionDenied, "registration requires invitation")
+	}
+}
+
+// CreateInvitation mints invitation code, it is returned only once.
+// Admins can mint invitations with any number of uses, other users only if it is allowed in config.
+func (s *Service) CreateInvitation(ctx context.Context, req *auth.CreateInvitationRequest) (*auth.CreateInvitationResponse, error) {
+	cfg := s.cfg.Registration
+
+	// 1. Get issuer from token
+	issuer, err := s.activeCaller(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	isAdmin := issuer.HasRole(models.RoleAdmin)
+	if !isAdmin && (!cfg.UserInvites || issuer.IsBot) {
+		return nil, status.Error(codes.PermissionDenied, "not enough permissions")
+	}
+
+	// 2. Validate limits
+	maxUses := int(req.MaxUses)
+	if maxUses == 0 {
+		maxUses = 1
+	}
+	if maxUses < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max uses must be positive")
+	}
+	if !isAdmin && maxUses > cfg.UserMaxUses {
+		return nil, status.Errorf(codes.InvalidArgument, "max uses is more than %d", cfg.UserMaxUses)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(cfg.InvitationTTL)
+	if req.ExpiresAt != 0 {
+		expiresAt = time.Unix(req.ExpiresAt, 0)
+	}
+	if !expiresAt.After(now) {
+		return nil, status.Error(codes.InvalidArgument, "expiration is in the past")
+	}
+	if expiresAt.After(now.Add(cfg.MaxInvitationTTL)) {
+		return nil, status.Errorf(codes.InvalidArgument, "expiration is later than %s", cfg.MaxInvitationTTL)
+	}
+
+	// 3. Generate and save code
+	code, hash, err := refresh_token.Generate()
+	if err != nil {
+		s.l.Error("Cant generate invitation code", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	inv := &models.Invitation{
+		CodeHash:  hash,
+		IssuerID:  &issuer.ID,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+	}
+	err = s.stInvitation.CreateInvitation(ctx, inv)
+	if err != nil {
+		s.l.Error("Cant create invitation", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit(ctx, models.AuthEventInvitationCreated, issuer.ID, "", "invitation "+inv.ID)
+
+	return &auth.CreateInvitationResponse{
+		Success:    true,
+		Invitation: inv.ToAuthInvitation(),
+		Code:       code,
+	}, nil
+}
+
+// ListInvitations returns invitations minted by the caller, newest first
+func (s *Service) ListInvitations(ctx context.Context, req *auth.ListInvitationsRequest) (*auth.ListInvitationsResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Find invitations
+	invs, err := s.stInvitation.ListInvitations(ctx, claims.Subject)
+	if err != nil {
+		s.l.Error("Cant list invitations", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &auth.ListInvitationsResponse{
+		Success:     true,
+		Invitations: make([]*auth.Invitation, 0, len(invs)),
+	}
+	for i := range invs {
+		resp.Invitations = append(resp.Invitations, invs[i].ToAuthInvitation())
+	}
+
+	return resp, nil
+}
+
+// RevokeInvitation stops invitation from being used, users who already signed up with it stay.
+// Issuer can revoke own invitations, admins can revoke any.
+func (s *Service) RevokeInvitation(ctx context.Context, req *auth.RevokeInvitationRequest) (*auth.RevokeInvitationResponse, error) {
+	// 1. Get user from token
+	caller, err := s.activeCaller(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Find invitation and check that caller can revoke it
+	inv, err := s.stInvitation.GetInvitation(ctx, req.InvitationId)
+	if err != nil {
+		s.l.Error("Cant get invitation", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	isIssuer := inv != nil && inv.IssuerID != nil && *inv.IssuerID == caller.ID
+	if inv == nil || (!isIssuer && !caller.HasRole(models.RoleAdmin)) {
+		return nil, status.Error(codes.NotFound, "invitation not found")
+	}
+
+	// 3. Revoke
+	err = s.stInvitation.RevokeInvitation(ctx, inv.ID)
+	if err != nil {
+		s.l.Error("Cant revoke invitation", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit(ctx, models.AuthEventInvitationRevoked, caller.ID, "", "invitation "+inv.ID)
+
+	return &auth.RevokeInvitationResponse{Success: true}, nil
+}
+
+// activeCaller returns active user of the token.
+// Returned error is already grpc status.
+func (s *Service) activeCaller(ctx context.Context, t *auth.Token) (*models.User, error) {
+	claims, err := s.parseToken(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.st.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err := checkActive(u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"auth_service/internal/lib/email_token"
+	"auth_service/internal/models"
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const loginCodeLength = 8
+
+// RequestLoginCode sends single-use code to login without password to email of the user.
+// It is throttled like password logins and response is the same for unknown email.
+func (s *Service) RequestLoginCode(ctx context.Context, req *auth.RequestLoginCodeRequest) (*auth.RequestLoginCodeResponse, error) {
+	// 1. Find user by email if client ip and account aren't blocked
+	u, _, err := s.findLoginUser(ctx, req.Email, s.st.FindUserByEmail)
+	if err != nil {
+		return nil, err
+	}
+	// Response is the same whether code is sent or not, errors after the user is found are only logged,
+	// so they can't be used to check if user exists
+	resp := &auth.RequestLoginCodeResponse{Success: true}
+
+	// bots have no real email
+	if u == nil || u.IsBot || checkActive(u) != nil {
+		return resp, nil
+	}
+
+	// 2. Don't send codes too often
+	c, err := s.stLoginCode.GetLoginCode(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant get login code", slog.String("error", err.Error()))
+		return resp, nil
+	}
+	if c != nil && time.Since(c.CreatedAt) < s.cfg.LoginCode.ResendCooldown {
+		return resp, nil
+	}
+
+	// 3. Create code, previous codes of the user stop working
+	code, err := email_token.GetRndEmailToken(loginCodeLength)
+	if err != nil {
+		s.l.Error("Cant generate login code", slog.String("error", err.Error()))
+		return resp, nil
+	}
+
+	expiresAt := time.Now().Add(s.cfg.LoginCode.CodeTTL)
+	err = s.stLoginCode.CreateLoginCode(ctx, u.ID, email_token.Hash(code), expiresAt)
+	if err != nil {
+		s.l.Error("Cant save login code", slog.String("error", err.Error()))
+		return resp, nil
+	}
+
+	// 4. Send code
+	err = s.notificationService.SendLoginCodeEmail(ctx, u.Email, code, expiresAt)
+	if err != nil {
+		s.l.Error("Cant use SendLoginCodeEmail (notifications service issue)", slog.String("error", err.Error()))
+		return resp, nil
+	}
+
+	s.audit(ctx, models.AuthEventLoginCodeSent, "", u.ID, "")
+
+	return resp, nil
+}
+
+// LoginWithCode exchanges code sent by RequestLoginCode for tokens, the same way as LoginByEmail.
+// Wrong codes are counted as failed logins.
+func (s *Service) LoginWithCode(ctx context.Context, req *auth.LoginWithCodeRequest) (*auth.LoginResponse, error) {
+	// 1. Find user by email if client ip and account aren't blocked
+	u, accountKey, err := s.findLoginUser(ctx, req.Email, s.st.FindUserByEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Check and use code
+	ok, err := s.useLoginCode(ctx, u, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		err = s.registerLoginFailure(ctx, u, accountKey, clientIP(ctx), "invalid login code")
+		if err != nil {
+			return nil, err
+		}
+		return nil, errInvalidCredentials
+	}
+
+	// 3. Generate tokens or challenge if second factor is required
+	return s.finishLogin(ctx, u)
+}
+
+// useLoginCode compares code with the latest code of the user and marks it as used,
+// u is nil for unknown account. Returned error is already grpc status.
+func (s *Service) useLoginCode(ctx context.Context, u *models.User, code string) (bool, error) {
+	if u == nil {
+		return false, nil
+	}
+
+	// 1. Find active code of the user
+	c, err := s.stLoginCode.GetLoginCode(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant get login code", slog.String("error", err.Error()))
+		return false, status.Error(codes.Internal, "internal error")
+	}
+	if c == nil || time.Now().After(c.ExpiresAt) || c.Attempts >= s.cfg.LoginCode.MaxAttempts {
+		return false, nil
+	}
+
+	// 2. Compare codes, every wrong guess is counted
+	if subtle.ConstantTimeCompare([]byte(email_token.Hash(code)), []byte(c.CodeHash)) != 1 {
+		err = s.stLoginCode.IncrementLoginCodeAttempts(ctx, c.ID)
+		if err != nil {
+			s.l.Error("Cant increment login code attempts", slog.String("error", err.Error()))
+			return false, status.Error(codes.Internal, "internal error")
+		}
+		return false, nil
+	}
+
+	// 3. Mark code as used
+	ok, err := s.stLoginCode.MarkLoginCodeUsed(ctx, c.ID)
+	if err != nil {
+		s.l.Error("Cant mark login code as used", slog.String("error", err.Error()))
+		return false, status.Error(codes.Internal, "internal error")
+	}
+
+	return ok, nil
+}
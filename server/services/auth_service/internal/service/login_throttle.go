@@ -0,0 +1,258 @@
+package service
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	accountThrottlePrefix = "user:"
+	loginThrottlePrefix   = "login:" // unknown accounts, so they are throttled the same way as existing
+	ipThrottlePrefix      = "ip:"
+)
+
+// login failures are the same for unknown user and wrong password,
+// so login rpcs can't be used to check if account exists
+var (
+	errInvalidCredentials = status.Error(codes.Unauthenticated, "invalid credentials")
+	errTooManyAttempts    = status.Error(codes.ResourceExhausted, "too many login attempts, try again later")
+)
+
+// login finds user with find and checks password, failed attempts are counted
+// per account and per client ip. Failures are reset only when login is finished
+// (after second factor if it is enabled). Returned error is already grpc status.
+func (s *Service) login(ctx context.Context, identifier, password string, find func(ctx context.Context, identifier string) (*models.User, error)) (*models.User, error) {
+	// 1. Find user if client ip and account aren't blocked
+	u, accountKey, err := s.findLoginUser(ctx, identifier, find)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Compare password, for unknown account or account without password dummy hash is verified,
+	// so response time doesn't tell if account exists
+	var ok, needsRehash bool
+	if u != nil && len(u.EncryptedPassword) > 0 {
+		ok, needsRehash = u.ComparePassword(s.passwords, password)
+	} else {
+		s.passwords.Verify(password, s.dummyPasswordHash())
+	}
+	if !ok {
+		err = s.registerLoginFailure(ctx, u, accountKey, clientIP(ctx), "invalid password")
+		if err != nil {
+			return nil, err
+		}
+		return nil, errInvalidCredentials
+	}
+
+	// 3. Replace old hash (other algorithm or parameters), it isn't critical for login
+	if needsRehash {
+		s.rehashPassword(ctx, u, password)
+	}
+
+	return u, nil
+}
+
+// dummyPasswordHash returns hash of random password made by current hasher,
+// it is created on first use and then reused
+func (s *Service) dummyPasswordHash() string {
+	s.dummyHashOnce.Do(func() {
+		h, err := s.passwords.Hash(uuid.NewString())
+		if err != nil {
+			s.l.Error("Cant hash dummy password", slog.String("error", err.Error()))
+			return
+		}
+		s.dummyHash = h
+	})
+
+	return s.dummyHash
+}
+
+// findLoginUser finds user with find if neither client ip nor account is blocked,
+// it is shared by all login methods. User is nil for unknown account, accountKey is
+// key failures are counted with. Returned error is already grpc status.
+func (s *Service) findLoginUser(ctx context.Context, identifier string, find func(ctx context.Context, identifier string) (*models.User, error)) (*models.User, string, error) {
+	// 1. Check if client ip is blocked
+	if ip := clientIP(ctx); ip != "" {
+		blocked, err := s.isLoginBlocked(ctx, ipThrottlePrefix+ip)
+		if err != nil {
+			return nil, "", err
+		}
+		if blocked {
+			return nil, "", errTooManyAttempts
+		}
+	}
+
+	// 2. Find user
+	u, err := find(ctx, identifier)
+	if err != nil {
+		s.l.Error("Cant find user", slog.String("error", err.Error()))
+		return nil, "", status.Error(codes.Internal, "internal error")
+	}
+
+	// 3. Check if account is blocked
+	accountKey := loginThrottlePrefix + strings.ToLower(identifier)
+	if u != nil {
+		accountKey = accountThrottlePrefix + u.ID
+	}
+	blocked, err := s.isLoginBlocked(ctx, accountKey)
+	if err != nil {
+		return nil, "", err
+	}
+	if blocked {
+		return nil, "", errTooManyAttempts
+	}
+
+	return u, accountKey, nil
+}
+
+// rehashPassword hashes password with current hasher and saves it, errors are only logged
+func (s *Service) rehashPassword(ctx context.Context, u *models.User, password string) {
+	u.Password = password
+	err := u.HashPassword(s.passwords)
+	if err != nil {
+		s.l.Error("Cant hash password", slog.String("error", err.Error()))
+		return
+	}
+
+	err = s.st.SetUserPassword(ctx, u.ID, u.EncryptedPassword)
+	if err != nil {
+		s.l.Error("Cant save rehashed password", slog.String("error", err.Error()))
+	}
+}
+
+// resetLoginFailures is called after successful login, only account failures are reset (not ip ones)
+func (s *Service) resetLoginFailures(ctx context.Context, u *models.User) error {
+	err := s.stLoginThrottle.ResetLoginFailures(ctx, accountThrottlePrefix+u.ID)
+	if err != nil {
+		s.l.Error("Cant reset login failures", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+	return nil
+}
+
+func (s *Service) isLoginBlocked(ctx context.Context, key string) (bool, error) {
+	t, err := s.stLoginThrottle.GetLoginThrottle(ctx, key)
+	if err != nil {
+		s.l.Error("Cant get login throttle", slog.String("error", err.Error()))
+		return false, status.Error(codes.Internal, "internal error")
+	}
+	return t != nil && t.IsBlocked(), nil
+}
+
+// registerLoginFailure counts failed attempt, blocks next attempts with exponential backoff
+// and locks account (notifying user) after too many failures. u is nil for unknown account.
+// Reason is saved in audit log.
+func (s *Service) registerLoginFailure(ctx context.Context, u *models.User, accountKey, ip, reason string) error {
+	cfg := s.cfg.LoginThrottle
+	now := time.Now()
+
+	if u != nil {
+		s.audit(ctx, models.AuthEventLoginFailed, "", u.ID, reason)
+	} else {
+		s.audit(ctx, models.AuthEventLoginFailed, "", "", "unknown account "+strings.TrimPrefix(accountKey, loginThrottlePrefix))
+	}
+
+	// 1. Account
+	t, err := s.stLoginThrottle.RegisterLoginFailure(ctx, accountKey, now.Add(-cfg.Window))
+	if err != nil {
+		s.l.Error("Cant register login failure", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	delay := s.loginBackoff(t.Failures, cfg.BackoffAfter)
+	locked := t.Failures >= cfg.LockoutAfter
+	if locked {
+		delay = cfg.LockoutDuration
+	}
+	if delay > 0 {
+		err = s.stLoginThrottle.BlockLogin(ctx, accountKey, now.Add(delay))
+		if err != nil {
+			s.l.Error("Cant block login", slog.String("error", err.Error()))
+			return status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	// notify only once, when account becomes locked
+	if u != nil && locked && t.Failures == cfg.LockoutAfter {
+		s.l.Warn("Account locked after failed logins", slog.String("user_id", u.ID), slog.String("ip", ip))
+
+		// failed notification shouldn't change login response
+		err = s.notificationService.SendAccountLockedEmail(ctx, u.Email, ip, now.Add(delay))
+		if err != nil {
+			s.l.Error("Cant use SendAccountLockedEmail (notifications service issue)", slog.String("error", err.Error()))
+		}
+	}
+
+	// 2. Client ip
+	if ip == "" {
+		return nil
+	}
+
+	t, err = s.stLoginThrottle.RegisterLoginFailure(ctx, ipThrottlePrefix+ip, now.Add(-cfg.Window))
+	if err != nil {
+		s.l.Error("Cant register login failure", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	delay = s.loginBackoff(t.Failures, cfg.IPBackoffAfter)
+	if delay > 0 {
+		err = s.stLoginThrottle.BlockLogin(ctx, ipThrottlePrefix+ip, now.Add(delay))
+		if err != nil {
+			s.l.Error("Cant block login", slog.String("error", err.Error()))
+			return status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	return nil
+}
+
+// loginBackoff returns time for which next attempts are blocked after given number of failures
+func (s *Service) loginBackoff(failures, after int) time.Duration {
+	cfg := s.cfg.LoginThrottle
+	if failures < after {
+		return 0
+	}
+
+	delay := cfg.BaseDelay
+	for i := after; i < failures && delay < cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > cfg.MaxDelay {
+		delay = cfg.MaxDelay
+	}
+
+	return delay
+}
+
+// PurgeLoginThrottleLoop deletes rows of keys which failures are outside of window
+// and which aren't blocked anymore, every PurgeInterval until ctx is done.
+// Without it rows of every unknown identifier tried to log in with would stay forever.
+func (s *Service) PurgeLoginThrottleLoop(ctx context.Context) {
+	cfg := s.cfg.LoginThrottle
+
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		n, err := s.stLoginThrottle.DeleteExpiredLoginThrottles(ctx, now.Add(-cfg.Window), now)
+		if err != nil {
+			s.l.Error("Cant delete expired login throttles", slog.String("error", err.Error()))
+		} else if n > 0 {
+			s.l.Debug("Deleted expired login throttles", slog.Int64("count", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
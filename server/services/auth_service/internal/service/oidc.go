@@ -0,0 +1,251 @@
+package service
+
+import (
+	"auth_service/internal/lib/email_token"
+	"auth_service/internal/lib/oidc"
+	"auth_service/internal/lib/validation"
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StartOIDCLogin returns url of provider login page, after login provider redirects user
+// to redirect url with code and state that must be passed to CompleteOIDCLogin.
+// If token is given provider account is linked to the user instead of login.
+func (s *Service) StartOIDCLogin(ctx context.Context, req *auth.StartOIDCLoginRequest) (*auth.StartOIDCLoginResponse, error) {
+	// 1. Find provider
+	p, ok := s.oidcProviders[req.Provider]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "provider not found")
+	}
+
+	// 2. Get user from token (only for linking)
+	var userID *string
+	if len(req.Token.GetToken()) > 0 {
+		claims, err := s.parseToken(ctx, req.Token)
+		if err != nil {
+			return nil, err
+		}
+		userID = &claims.Subject
+	}
+
+	// 3. Create state, nonce and PKCE code verifier
+	st := &models.OIDCState{
+		Provider:  req.Provider,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(s.cfg.OIDC.StateTTL),
+	}
+	var err error
+	for _, v := range []*string{&st.State, &st.Nonce, &st.CodeVerifier} {
+		*v, err = oidc.RandomString()
+		if err != nil {
+			s.l.Error("Cant generate oidc state", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	// 4. Build url of provider login page
+	authURL, err := p.AuthCodeURL(ctx, st.State, st.Nonce, oidc.CodeChallenge(st.CodeVerifier))
+	if err != nil {
+		s.l.Error("Cant get oidc auth url", slog.String("provider", req.Provider), slog.String("error", err.Error()))
+		return nil, status.Error(codes.Unavailable, "provider is unavailable")
+	}
+
+	err = s.stUserIdentity.CreateOIDCState(ctx, st)
+	if err != nil {
+		s.l.Error("Cant save oidc state", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.StartOIDCLoginResponse{
+		Success: true,
+		AuthUrl: authURL,
+		State:   st.State,
+	}, nil
+}
+
+func (s *Service) CompleteOIDCLogin(ctx context.Context, req *auth.CompleteOIDCLoginRequest) (*auth.LoginResponse, error) {
+	// 1. Find state, it can be used only once
+	st, err := s.stUserIdentity.TakeOIDCState(ctx, req.State)
+	if err != nil {
+		s.l.Error("Cant get oidc state", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if st == nil || time.Now().After(st.ExpiresAt) {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired state")
+	}
+
+	p, ok := s.oidcProviders[st.Provider]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "provider not found")
+	}
+
+	// 2. Exchange code for id_token and verify it
+	rawIDToken, err := p.Exchange(ctx, req.Code, st.CodeVerifier)
+	if err != nil {
+		s.l.Warn("Cant exchange oidc code", slog.String("provider", st.Provider), slog.String("error", err.Error()))
+		return nil, status.Error(codes.Unauthenticated, "login with provider failed")
+	}
+
+	idToken, err := p.VerifyIDToken(ctx, rawIDToken, st.Nonce)
+	if err != nil {
+		s.l.Warn("Invalid oidc id_token", slog.String("provider", st.Provider), slog.String("error", err.Error()))
+		return nil, status.Error(codes.Unauthenticated, "login with provider failed")
+	}
+
+	// 3. Find or create user linked to provider account
+	u, err := s.userForIdentity(ctx, st, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Generate tokens or challenge if second factor is required
+	return s.finishLogin(ctx, u)
+}
+
+// userForIdentity returns user linked to provider account, links it if it isn't linked yet.
+// Returned error is already grpc status.
+func (s *Service) userForIdentity(ctx context.Context, st *models.OIDCState, idToken *oidc.IDToken) (*models.User, error) {
+	identity, err := s.stUserIdentity.GetUserIdentity(ctx, st.Provider, idToken.Subject)
+	if err != nil {
+		s.l.Error("Cant get user identity", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	var u *models.User
+	switch {
+	case identity != nil:
+		if st.UserID != nil && *st.UserID != identity.UserID {
+			return nil, status.Error(codes.AlreadyExists, "provider account is linked to another user")
+		}
+		u, err = s.st.GetUserByID(ctx, identity.UserID)
+	case st.UserID != nil:
+		u, err = s.st.GetUserByID(ctx, *st.UserID)
+	default:
+		u, err = s.st.FindUserByEmail(ctx, idToken.Email)
+		if err == nil && u != nil && !(s.linkByEmail(st.Provider) && bool(idToken.EmailVerified)) {
+			return nil, status.Error(codes.FailedPrecondition, "user with this email already exists, login and link provider to the account")
+		}
+	}
+	if err != nil {
+		s.l.Error("Cant get user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if identity != nil {
+		if u == nil {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return u, nil
+	}
+
+	if u == nil {
+		if st.UserID != nil {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		u, err = s.createOIDCUser(ctx, idToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.stUserIdentity.CreateUserIdentity(ctx, &models.UserIdentity{
+		UserID:   u.ID,
+		Provider: st.Provider,
+		Subject:  idToken.Subject,
+		Email:    idToken.Email,
+	})
+	if err != nil {
+		s.l.Error("Cant create user identity", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return u, nil
+}
+
+// createOIDCUser creates user without password, user can set it with password reset
+func (s *Service) createOIDCUser(ctx context.Context, idToken *oidc.IDToken) (*models.User, error) {
+	if idToken.Email == "" {
+		return nil, status.Error(codes.FailedPrecondition, "provider didn't return email")
+	}
+
+	// there is no way to pass invitation with oidc login
+	if _, err := s.checkRegistration(ctx, idToken.Email, ""); err != nil {
+		return nil, err
+	}
+
+	username, err := s.freeUsername(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &models.User{
+		Username:          username,
+		Email:             idToken.Email,
+		ConfirmedEmail:    bool(idToken.EmailVerified),
+		EncryptedPassword: []byte{}, // no password matches empty hash
+		CreatedAt:         time.Now(),
+	}
+	err = s.st.CreateUser(ctx, u)
+	if err != nil {
+		s.l.Error("Cant create user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// email that provider didn't verify is verified with code like in CreateUser,
+	// login isn't failed if code isn't sent (error is logged), it can be sent again with ResendVerification
+	if !u.ConfirmedEmail {
+		_ = s.sendVerificationCode(ctx, u)
+	}
+
+	return u, nil
+}
+
+// freeUsername returns username based on provider claims that isn't used yet
+func (s *Service) freeUsername(ctx context.Context, idToken *oidc.IDToken) (string, error) {
+	const tries = 5
+
+	base := idToken.PreferredUsername
+	if validation.Username(base) != nil {
+		base, _, _ = strings.Cut(idToken.Email, "@")
+	}
+	if validation.Username(base) != nil {
+		base = "user"
+	}
+
+	username := base
+	for i := 0; i < tries; i++ {
+		u, err := s.st.FindUserByUsername(ctx, username)
+		if err != nil {
+			s.l.Error("Cant find user by username", slog.String("error", err.Error()))
+			return "", status.Error(codes.Internal, "internal error")
+		}
+		if u == nil {
+			return username, nil
+		}
+
+		suffix, err := email_token.GetRndEmailToken(4)
+		if err != nil {
+			s.l.Error("Cant generate username suffix", slog.String("error", err.Error()))
+			return "", status.Error(codes.Internal, "internal error")
+		}
+		username = base + "_" + suffix
+	}
+
+	return "", status.Error(codes.AlreadyExists, "cant find free username")
+}
+
+func (s *Service) linkByEmail(provider string) bool {
+	for _, p := range s.cfg.OIDC.Providers {
+		if p.Name == provider {
+			return p.LinkByEmail
+		}
+	}
+	return false
+}
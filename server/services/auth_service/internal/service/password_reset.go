@@ -0,0 +1,142 @@
+package service
+
+import (
+	"auth_service/internal/lib/email_token"
+	"auth_service/internal/lib/validation"
+	"auth_service/internal/models"
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const passwordResetCodeLength = 8
+
+// RequestPasswordReset sends single-use code to reset password to email of the user.
+// Response is the same for unknown email, so this rpc can't be used to check if user exists.
+func (s *Service) RequestPasswordReset(ctx context.Context, req *auth.RequestPasswordResetRequest) (*auth.RequestPasswordResetResponse, error) {
+	// 1. Find user by email
+	u, err := s.st.FindUserByEmail(ctx, req.Email)
+	if err != nil {
+		s.l.Error("Cant find user by email", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// errors after the user is found are only logged, so they can't be used to check if user exists too
+	resp := &auth.RequestPasswordResetResponse{Success: true}
+	if u == nil {
+		return resp, nil
+	}
+
+	// 2. Don't send codes too often, so mailbox of the user can't be flooded
+	c, err := s.stPasswordReset.GetPasswordResetCode(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant get password reset code", slog.String("error", err.Error()))
+		return resp, nil
+	}
+	if c != nil && time.Since(c.CreatedAt) < s.cfg.PasswordReset.ResendCooldown {
+		return resp, nil
+	}
+
+	// 3. Create code, previous codes of the user stop working
+	code, err := email_token.GetRndEmailToken(passwordResetCodeLength)
+	if err != nil {
+		s.l.Error("Cant generate password reset code", slog.String("error", err.Error()))
+		return resp, nil
+	}
+
+	err = s.stPasswordReset.CreatePasswordResetCode(ctx, u.ID, email_token.Hash(code), time.Now().Add(s.cfg.PasswordReset.CodeTTL))
+	if err != nil {
+		s.l.Error("Cant save password reset code", slog.String("error", err.Error()))
+		return resp, nil
+	}
+
+	// 4. Send code
+	err = s.notificationService.SendPasswordResetEmail(ctx, code, u.Email)
+	if err != nil {
+		s.l.Error("Cant use SendPasswordResetEmail (notifications service issue)", slog.String("error", err.Error()))
+		return resp, nil
+	}
+
+	return resp, nil
+}
+
+func (s *Service) ResetPassword(ctx context.Context, req *auth.ResetPasswordRequest) (*auth.ResetPasswordResponse, error) {
+	// 1. Find user by email
+	u, err := s.st.FindUserByEmail(ctx, req.Email)
+	if err != nil {
+		s.l.Error("Cant find user by email", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+	}
+
+	// 2. Find active code of the user
+	code, err := s.stPasswordReset.GetPasswordResetCode(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant get password reset code", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if code == nil || time.Now().After(code.ExpiresAt) || code.Attempts >= s.cfg.PasswordReset.MaxAttempts {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+	}
+
+	// 3. Compare codes, every wrong guess is counted
+	if subtle.ConstantTimeCompare([]byte(email_token.Hash(req.Code)), []byte(code.CodeHash)) != 1 {
+		err = s.stPasswordReset.IncrementPasswordResetAttempts(ctx, code.ID)
+		if err != nil {
+			s.l.Error("Cant increment password reset attempts", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+	}
+
+	// 4. Check new password, code stays valid if it is rejected
+	var v validation.Violations
+	v.Add("new_password", s.passwordPolicy.Validate(req.NewPassword, u.Username, u.Email))
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	// 5. Mark code as used
+	ok, err := s.stPasswordReset.MarkPasswordResetCodeUsed(ctx, code.ID)
+	if err != nil {
+		s.l.Error("Cant mark password reset code as used", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+	}
+
+	// 6. Hash and save new password
+	u.Password = req.NewPassword
+	err = u.HashPassword(s.passwords)
+	if err != nil {
+		s.l.Error("Cant hash password", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.st.UpdateUser(ctx, u)
+	if err != nil {
+		s.l.Error("Cant update user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit(ctx, models.AuthEventPasswordReset, "", u.ID, "")
+
+	// 7. Logout everywhere, old password could be known to someone else
+	err = s.revokeAllSessions(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant revoke sessions", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.ResetPasswordResponse{
+		Success: true,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"time"
+	_ "time/tzdata" // time zones are validated even if system has no tz database
+	"unicode/utf8"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxDisplayNameLength = 64
+	maxBioLength         = 500
+	maxAvatarRefLength   = 512
+	maxStatusTextLength  = 128
+
+	maxProfilesBatch = 1000
+)
+
+func (s *Service) GetProfile(ctx context.Context, req *auth.GetProfileRequest) (*auth.GetProfileResponse, error) {
+	// 1. Find profile
+	profiles, err := s.stProfile.GetProfiles(ctx, []string{req.UserId})
+	if err != nil {
+		s.l.Error("Cant get profile", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if len(profiles) == 0 {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	return &auth.GetProfileResponse{
+		Success: true,
+		Profile: profiles[0].ToAuthProfile(),
+	}, nil
+}
+
+// GetProfiles is batch lookup for other services (e.g. to show room members),
+// unknown ids are skipped
+func (s *Service) GetProfiles(ctx context.Context, req *auth.GetProfilesRequest) (*auth.GetProfilesResponse, error) {
+	if len(req.UserIds) > maxProfilesBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "too many ids, max is %d", maxProfilesBatch)
+	}
+	if len(req.UserIds) == 0 {
+		return &auth.GetProfilesResponse{Success: true}, nil
+	}
+
+	// 1. Find profiles
+	profiles, err := s.stProfile.GetProfiles(ctx, req.UserIds)
+	if err != nil {
+		s.l.Error("Cant get profiles", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &auth.GetProfilesResponse{
+		Success:  true,
+		Profiles: make([]*auth.Profile, 0, len(profiles)),
+	}
+	for i := range profiles {
+		resp.Profiles = append(resp.Profiles, profiles[i].ToAuthProfile())
+	}
+
+	return resp, nil
+}
+
+// UpdateProfile replaces profile of the user with given one
+func (s *Service) UpdateProfile(ctx context.Context, req *auth.UpdateProfileRequest) (*auth.UpdateProfileResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Validate
+	switch {
+	case utf8.RuneCountInString(req.DisplayName) > maxDisplayNameLength:
+		return nil, status.Errorf(codes.InvalidArgument, "display name is longer than %d characters", maxDisplayNameLength)
+	case utf8.RuneCountInString(req.Bio) > maxBioLength:
+		return nil, status.Errorf(codes.InvalidArgument, "bio is longer than %d characters", maxBioLength)
+	case len(req.AvatarRef) > maxAvatarRefLength:
+		return nil, status.Error(codes.InvalidArgument, "avatar reference is too long")
+	case utf8.RuneCountInString(req.StatusText) > maxStatusTextLength:
+		return nil, status.Errorf(codes.InvalidArgument, "status is longer than %d characters", maxStatusTextLength)
+	}
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "unknown time zone")
+		}
+	}
+
+	profile := &models.Profile{
+		UserID:      claims.Subject,
+		Username:    claims.Username,
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		AvatarRef:   req.AvatarRef,
+		TimeZone:    req.TimeZone,
+		StatusText:  req.StatusText,
+	}
+	if req.StatusExpiresAt != 0 {
+		expiresAt := time.Unix(req.StatusExpiresAt, 0)
+		if expiresAt.Before(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "status expiration is in the past")
+		}
+		profile.StatusExpiresAt = &expiresAt
+	}
+
+	// 3. Save
+	err = s.stProfile.UpsertProfile(ctx, profile)
+	if err != nil {
+		s.l.Error("Cant update profile", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.UpdateProfileResponse{
+		Success: true,
+		Profile: profile.ToAuthProfile(),
+	}, nil
+}
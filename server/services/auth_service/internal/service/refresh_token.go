@@ -0,0 +1,125 @@
+package service
+
+import (
+	"auth_service/internal/lib/refresh_token"
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Service) RefreshToken(ctx context.Context, req *auth.RefreshTokenRequest) (*auth.RefreshTokenResponse, error) {
+	// 1. Find refresh token by its hash
+	rt, err := s.stRefreshToken.GetRefreshTokenByHash(ctx, refresh_token.Hash(req.RefreshToken))
+	if err != nil {
+		s.l.Error("Cant get refresh token", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if rt == nil || rt.Revoked || rt.IsExpired() {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	// 2. Mark token as used, if it was used before someone is reusing it,
+	// so whole family is revoked (both attacker and real user must login again)
+	if rt.Used {
+		return nil, s.revokeReusedFamily(ctx, rt)
+	}
+	ok, err := s.stRefreshToken.MarkRefreshTokenUsed(ctx, rt.ID)
+	if err != nil {
+		s.l.Error("Cant mark refresh token as used", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if !ok {
+		return nil, s.revokeReusedFamily(ctx, rt)
+	}
+
+	// 3. Find user
+	u, err := s.st.GetUserByID(ctx, rt.UserID)
+	if err != nil {
+		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err := checkActive(u); err != nil {
+		return nil, err
+	}
+
+	// 4. Update last seen time of the session
+	err = s.stSession.TouchSession(ctx, rt.FamilyID, clientIP(ctx))
+	if err != nil {
+		s.l.Error("Cant update session", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// 5. Generate new tokens in the same session
+	token, refreshToken, err := s.issueTokens(ctx, u, rt.FamilyID)
+	if err != nil {
+		s.l.Error("Cant create tokens", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit(ctx, models.AuthEventTokenRefreshed, u.ID, u.ID, "session "+rt.FamilyID)
+
+	return &auth.RefreshTokenResponse{
+		Success:      true,
+		Token:        &auth.Token{Token: token},
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// revokeReusedFamily revokes session of reused token with all its refresh tokens
+// and returns error to be sent to client (status.Error)
+func (s *Service) revokeReusedFamily(ctx context.Context, rt *models.RefreshToken) error {
+	s.l.Warn("Refresh token reuse detected, revoking token family",
+		slog.String("user_id", rt.UserID),
+		slog.String("family_id", rt.FamilyID),
+	)
+
+	err := s.stSession.RevokeSession(ctx, rt.FamilyID)
+	if err != nil {
+		s.l.Error("Cant revoke session", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid refresh token")
+}
+
+// issueTokens creates access token and refresh token for the user.
+// If familyID is empty new refresh token family is started.
+func (s *Service) issueTokens(ctx context.Context, u *models.User, sessionID string) ([]byte, string, error) {
+	if sessionID == "" {
+		session, err := s.newSession(ctx, u.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		sessionID = session.ID
+	}
+
+	token, err := s.tokenManager.CreateToken(u, sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshToken, hash, err := refresh_token.Generate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = s.stRefreshToken.CreateRefreshToken(ctx, &models.RefreshToken{
+		UserID:    u.ID,
+		FamilyID:  sessionID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.cfg.Tokens.RefreshTokenTTL),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return token, refreshToken, nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"auth_service/internal/lib/refresh_token"
+	"auth_service/internal/lib/token"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Service) Logout(ctx context.Context, req *auth.LogoutRequest) (*auth.LogoutResponse, error) {
+	// 1. Parse token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Revoke access token
+	err = s.stRevokedToken.RevokeToken(ctx, claims.Id, claims.Subject, claims.ExpiresAtTime())
+	if err != nil {
+		s.l.Error("Cant revoke token", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	// 3. Revoke session of this login with its refresh tokens
+	if claims.SessionID != "" {
+		err = s.stSession.RevokeSession(ctx, claims.SessionID)
+		if err != nil {
+			s.l.Error("Cant revoke session", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	// tokens issued before sessions existed have no sid, so session is found by refresh token
+	if req.RefreshToken != "" {
+		rt, err := s.stRefreshToken.GetRefreshTokenByHash(ctx, refresh_token.Hash(req.RefreshToken))
+		if err != nil {
+			s.l.Error("Cant get refresh token", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+
+		if rt != nil && rt.UserID == claims.Subject {
+			err = s.stSession.RevokeSession(ctx, rt.FamilyID)
+			if err != nil {
+				s.l.Error("Cant revoke session", slog.String("error", err.Error()))
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+		}
+	}
+
+	return &auth.LogoutResponse{
+		Success: true,
+	}, nil
+}
+
+// RevokeToken revokes another token of the same user (e.g. leaked one)
+func (s *Service) RevokeToken(ctx context.Context, req *auth.RevokeTokenRequest) (*auth.RevokeTokenResponse, error) {
+	// 1. Parse token of the caller
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Parse token to revoke, expired tokens can't be used anyway
+	target, err := s.tokenManager.ParseToken(req.TokenToRevoke.GetToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid token to revoke")
+	}
+	if target.Subject != claims.Subject {
+		return nil, status.Error(codes.PermissionDenied, "token belongs to another user")
+	}
+
+	// 3. Revoke it
+	err = s.stRevokedToken.RevokeToken(ctx, target.Id, target.Subject, target.ExpiresAtTime())
+	if err != nil {
+		s.l.Error("Cant revoke token", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.RevokeTokenResponse{
+		Success: true,
+	}, nil
+}
+
+// revokeAllSessions revokes all sessions, access and refresh tokens of the user
+func (s *Service) revokeAllSessions(ctx context.Context, userID string) error {
+	err := s.stSession.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.stRefreshToken.RevokeUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// "iat" claim has seconds precision, so tokens issued in the same second
+	// as revocation are revoked too, user can just log in again
+	return s.stRevokedToken.RevokeUserTokens(ctx, userID, time.Now())
+}
+
+// parseToken parses token and checks that neither it nor its session was revoked
+// returns claims if token is valid, otherwise returns error to be sent to client (status.Error)
+func (s *Service) parseToken(ctx context.Context, t *auth.Token) (*token.Claims, error) {
+	claims, err := s.tokenManager.ParseToken(t.GetToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid token")
+	}
+
+	revoked, err := s.stRevokedToken.IsTokenRevoked(ctx, claims.Id, claims.Subject, claims.IssuedAtTime())
+	if err != nil {
+		s.l.Error("Cant check if token is revoked", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if revoked {
+		return nil, status.Error(codes.Unauthenticated, "token revoked")
+	}
+
+	// tokens issued before sessions existed have no sid
+	if claims.SessionID != "" {
+		session, err := s.stSession.GetSession(ctx, claims.SessionID)
+		if err != nil {
+			s.l.Error("Cant get session", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if session == nil || session.Revoked || session.UserID != claims.Subject {
+			return nil, status.Error(codes.Unauthenticated, "session revoked")
+		}
+	}
+
+	return claims, nil
+}
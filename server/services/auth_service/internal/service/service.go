@@ -1,12 +1,21 @@
 package service
 
 import (
+	"auth_service/internal/client/chat"
 	"auth_service/internal/client/notifications"
+	"auth_service/internal/client/rooms"
+	"auth_service/internal/config"
+	"auth_service/internal/lib/api_key"
 	"auth_service/internal/lib/email_token"
+	"auth_service/internal/lib/oidc"
+	"auth_service/internal/lib/token"
+	"auth_service/internal/lib/validation"
 	"auth_service/internal/models"
 	"context"
-	"fmt"
+	"crypto/subtle"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/zumosik/grpc_chat_protos/go/auth"
 	"google.golang.org/grpc"
@@ -14,51 +23,278 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// TODO: add validation
+// maxUsersBatch is max number of ids in GetUsersByIDs
+const maxUsersBatch = 1000
+
+// Fields of user that can be changed with UpdateUser
+const (
+	updateFieldUsername = "username"
+	updateFieldEmail    = "email"
+	updateFieldPassword = "password"
+)
 
 type EmailTokenStorage interface {
-	CreateEmailToken(ctx context.Context, token, userID string) error
-	GetUserIDByToken(ctx context.Context, token string) (string, error)
-	DeleteEmailToken(ctx context.Context, token string) error
+	CreateEmailToken(ctx context.Context, userID, codeHash string, expiresAt time.Time) error
+	GetEmailToken(ctx context.Context, userID string) (*models.EmailToken, error)
+	IncrementEmailTokenAttempts(ctx context.Context, id int) error
+	DeleteEmailTokens(ctx context.Context, userID string) error
+}
+
+type RefreshTokenStorage interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id string) (bool, error)
+	RevokeUserRefreshTokens(ctx context.Context, userID string) error
+}
+
+type RevokedTokenStorage interface {
+	RevokeToken(ctx context.Context, jti, userID string, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, userID string, before time.Time) error
+	IsTokenRevoked(ctx context.Context, jti, userID string, issuedAt time.Time) (bool, error)
+}
+
+type PasswordResetStorage interface {
+	CreatePasswordResetCode(ctx context.Context, userID, codeHash string, expiresAt time.Time) error
+	GetPasswordResetCode(ctx context.Context, userID string) (*models.PasswordResetCode, error)
+	IncrementPasswordResetAttempts(ctx context.Context, id int) error
+	MarkPasswordResetCodeUsed(ctx context.Context, id int) (bool, error)
+}
+
+type LoginThrottleStorage interface {
+	GetLoginThrottle(ctx context.Context, key string) (*models.LoginThrottle, error)
+	RegisterLoginFailure(ctx context.Context, key string, windowStart time.Time) (*models.LoginThrottle, error)
+	BlockLogin(ctx context.Context, key string, until time.Time) error
+	ResetLoginFailures(ctx context.Context, key string) error
+	DeleteExpiredLoginThrottles(ctx context.Context, windowStart, now time.Time) (int64, error)
+}
+
+type TwoFactorStorage interface {
+	SaveTOTPSecret(ctx context.Context, userID, secret string) error
+	GetTOTP(ctx context.Context, userID string) (*models.TOTP, error)
+	EnableTOTP(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, userID string) error
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+
+	CreateLoginChallenge(ctx context.Context, userID, challengeHash string, expiresAt time.Time) error
+	GetLoginChallengeByHash(ctx context.Context, hash string) (*models.LoginChallenge, error)
+	IncrementLoginChallengeAttempts(ctx context.Context, id int) error
+	MarkLoginChallengeUsed(ctx context.Context, id int) (bool, error)
+}
+
+type SessionStorage interface {
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, id string) (*models.Session, error)
+	ListUserSessions(ctx context.Context, userID string, activeSince time.Time) ([]models.Session, error)
+	TouchSession(ctx context.Context, id, ip string) error
+	RevokeSession(ctx context.Context, id string) error
+	RevokeUserSessions(ctx context.Context, userID string) error
+}
+
+type UserIdentityStorage interface {
+	CreateOIDCState(ctx context.Context, state *models.OIDCState) error
+	TakeOIDCState(ctx context.Context, state string) (*models.OIDCState, error)
+
+	CreateUserIdentity(ctx context.Context, identity *models.UserIdentity) error
+	GetUserIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	ListUserIdentities(ctx context.Context, userID string) ([]models.UserIdentity, error)
+}
+
+type APIKeyStorage interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKey(ctx context.Context, id string) (*models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error)
+	TouchAPIKey(ctx context.Context, id string) error
+	RevokeAPIKey(ctx context.Context, id string) error
+}
+
+type ProfileStorage interface {
+	GetProfiles(ctx context.Context, userIDs []string) ([]models.Profile, error)
+	UpsertProfile(ctx context.Context, profile *models.Profile) error
+}
+
+type EmailChangeStorage interface {
+	CreateEmailChange(ctx context.Context, change *models.EmailChange) error
+	GetEmailChange(ctx context.Context, userID string) (*models.EmailChange, error)
+	IncrementEmailChangeAttempts(ctx context.Context, userID string) error
+	ApplyEmailChange(ctx context.Context, userID, newEmail string) error
+}
+
+type UserEventStorage interface {
+	ListUserEvents(ctx context.Context, afterID int64, limit int) ([]models.UserEvent, error)
+}
+
+type DataExportStorage interface {
+	CreateDataExport(ctx context.Context, export *models.DataExport) error
+	GetDataExport(ctx context.Context, id string) (*models.DataExport, error)
+	GetLatestDataExport(ctx context.Context, userID string) (*models.DataExport, error)
+	FinishDataExport(ctx context.Context, export *models.DataExport) error
+	IncrementDataExportAttempts(ctx context.Context, id string) error
+	DeleteExpiredDataExports(ctx context.Context, before time.Time) ([]models.DataExport, error)
+}
+
+type AuthEventStorage interface {
+	CreateAuthEvent(ctx context.Context, event *models.AuthEvent) error
+	ListAuthEvents(ctx context.Context, filter models.AuthEventFilter) ([]models.AuthEvent, error)
+}
+
+type LoginCodeStorage interface {
+	CreateLoginCode(ctx context.Context, userID, codeHash string, expiresAt time.Time) error
+	GetLoginCode(ctx context.Context, userID string) (*models.LoginCode, error)
+	IncrementLoginCodeAttempts(ctx context.Context, id int) error
+	MarkLoginCodeUsed(ctx context.Context, id int) (bool, error)
+}
+
+type InvitationStorage interface {
+	CreateInvitation(ctx context.Context, inv *models.Invitation) error
+	GetInvitation(ctx context.Context, id string) (*models.Invitation, error)
+	GetInvitationByHash(ctx context.Context, hash string) (*models.Invitation, error)
+	ListInvitations(ctx context.Context, issuerID string) ([]models.Invitation, error)
+	RevokeInvitation(ctx context.Context, id string) error
+	CreateInvitedUser(ctx context.Context, user *models.User, codeHash string) (bool, error)
+}
+
+type UserBlockStorage interface {
+	CreateUserBlock(ctx context.Context, blockerID, blockedID string) error
+	DeleteUserBlock(ctx context.Context, blockerID, blockedID string) (bool, error)
+	ListUserBlocks(ctx context.Context, blockerID string) ([]models.UserBlock, error)
+	GetBlockRelations(ctx context.Context, userID string, otherIDs []string) (blockedBy, blocked []string, err error)
 }
 
 type UserStorage interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	UpdateUser(ctx context.Context, user *models.User) error
-	DeleteUser(ctx context.Context, id string) error
+	SetUserDeleted(ctx context.Context, id string, deletedAt *time.Time) error
+	PurgeDeletedUsers(ctx context.Context, before time.Time) ([]string, error)
 
 	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, ids []string) ([]models.User, error)
 
 	FindUserByEmail(ctx context.Context, email string) (*models.User, error)
 	FindUserByUsername(ctx context.Context, username string) (*models.User, error)
+	SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserSearchResult, error)
+
+	ListUsers(ctx context.Context, filter models.UserFilter) ([]models.User, error)
+	SetUserSuspended(ctx context.Context, id string, suspendedAt *time.Time, reason string) error
+	SetUserRole(ctx context.Context, id, role string) error
+	SetUserPassword(ctx context.Context, id string, encryptedPassword []byte) error
 }
 
 type TokenManager interface {
-	CreateToken(u *models.User) ([]byte, error)
-	ParseToken(token []byte) (string, error)
+	CreateToken(u *models.User, sessionID string) ([]byte, error)
+	ParseToken(token []byte) (*token.Claims, error)
+	PublicKeys() []token.JWK
 }
 
-type Service struct {
-	st           UserStorage
-	stEmailToken EmailTokenStorage
+// OIDCProvider is OpenID Connect provider (see oidc.Provider)
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier string) (string, error)
+	VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*oidc.IDToken, error)
+}
 
-	tokenManager TokenManager
-	l            *slog.Logger
+type Service struct {
+	st              UserStorage
+	stEmailToken    EmailTokenStorage
+	stRefreshToken  RefreshTokenStorage
+	stRevokedToken  RevokedTokenStorage
+	stPasswordReset PasswordResetStorage
+	stLoginThrottle LoginThrottleStorage
+	stTwoFactor     TwoFactorStorage
+	stSession       SessionStorage
+	stUserIdentity  UserIdentityStorage
+	stAPIKey        APIKeyStorage
+	stProfile       ProfileStorage
+	stEmailChange   EmailChangeStorage
+	stUserEvent     UserEventStorage
+	stDataExport    DataExportStorage
+	stAuthEvent     AuthEventStorage
+	stUserBlock     UserBlockStorage
+	stLoginCode     LoginCodeStorage
+	stInvitation    InvitationStorage
+
+	tokenManager   TokenManager
+	passwords      models.PasswordHasher
+	passwordPolicy *validation.PasswordPolicy
+	oidcProviders  map[string]OIDCProvider
+	cfg            *config.Config
+	l              *slog.Logger
 
 	notificationService *notifications.Client
+	roomsService        *rooms.Client
+	chatService         *chat.Client
+
+	// dummyHash is verified instead of hash of unknown account, see dummyPasswordHash
+	dummyHashOnce sync.Once
+	dummyHash     string
+
+	// data export jobs outlive requests, they are canceled and waited for in StopDataExports
+	exportsCtx  context.Context
+	stopExports context.CancelFunc
+	exports     sync.WaitGroup
 
 	auth.UnimplementedAuthServiceServer
 }
 
-func New(logger *slog.Logger, storage UserStorage, stEmailToken EmailTokenStorage, tokenManager TokenManager, notificationService *notifications.Client) *Service {
-	return &Service{
-		st:           storage,
-		stEmailToken: stEmailToken,
+// Storages groups storages used by Service, one storage (see postgres.Storage) can implement all of them
+type Storages struct {
+	User          UserStorage
+	EmailToken    EmailTokenStorage
+	RefreshToken  RefreshTokenStorage
+	RevokedToken  RevokedTokenStorage
+	PasswordReset PasswordResetStorage
+	LoginThrottle LoginThrottleStorage
+	TwoFactor     TwoFactorStorage
+	Session       SessionStorage
+	UserIdentity  UserIdentityStorage
+	APIKey        APIKeyStorage
+	Profile       ProfileStorage
+	EmailChange   EmailChangeStorage
+	UserEvent     UserEventStorage
+	DataExport    DataExportStorage
+	AuthEvent     AuthEventStorage
+	UserBlock     UserBlockStorage
+	LoginCode     LoginCodeStorage
+	Invitation    InvitationStorage
+}
 
-		l:            logger,
-		tokenManager: tokenManager,
+func New(logger *slog.Logger, cfg *config.Config, st Storages, tokenManager TokenManager, passwords models.PasswordHasher, passwordPolicy *validation.PasswordPolicy, oidcProviders map[string]OIDCProvider, notificationService *notifications.Client, roomsService *rooms.Client, chatService *chat.Client) *Service {
+	exportsCtx, stopExports := context.WithCancel(context.Background())
+
+	return &Service{
+		st:              st.User,
+		stEmailToken:    st.EmailToken,
+		stRefreshToken:  st.RefreshToken,
+		stRevokedToken:  st.RevokedToken,
+		stPasswordReset: st.PasswordReset,
+		stLoginThrottle: st.LoginThrottle,
+		stTwoFactor:     st.TwoFactor,
+		stSession:       st.Session,
+		stUserIdentity:  st.UserIdentity,
+		stAPIKey:        st.APIKey,
+		stProfile:       st.Profile,
+		stEmailChange:   st.EmailChange,
+		stUserEvent:     st.UserEvent,
+		stDataExport:    st.DataExport,
+		stAuthEvent:     st.AuthEvent,
+		stUserBlock:     st.UserBlock,
+		stLoginCode:     st.LoginCode,
+		stInvitation:    st.Invitation,
+
+		l:              logger,
+		cfg:            cfg,
+		tokenManager:   tokenManager,
+		passwords:      passwords,
+		passwordPolicy: passwordPolicy,
+		oidcProviders:  oidcProviders,
 
 		notificationService: notificationService,
+		roomsService:        roomsService,
+		chatService:         chatService,
+
+		exportsCtx:  exportsCtx,
+		stopExports: stopExports,
 	}
 }
 
@@ -67,61 +303,39 @@ func Register(server *grpc.Server, service *Service) {
 }
 
 func (s *Service) LoginByUsername(ctx context.Context, request *auth.LoginRequestByUsername) (*auth.LoginResponse, error) {
-	// 1. Find user by username
-	u, err := s.st.FindUserByUsername(ctx, request.Username)
-	if err != nil {
-		s.l.Error("Cant find user by username", slog.String("error", err.Error()))
-		return nil, status.Error(codes.Internal, "internal error")
-	}
-	if u == nil {
-		return nil, status.Error(codes.NotFound, "user not found")
-	}
-	// 2. Compare password
-	if !u.ComparePassword(request.Password) {
-		return nil, status.Error(codes.Unauthenticated, "wrong password")
-	}
-	// 3. Generate token
-	token, err := s.tokenManager.CreateToken(u)
+	// 1. Find user by username and compare password (failed attempts are throttled)
+	u, err := s.login(ctx, request.Username, request.Password, s.st.FindUserByUsername)
 	if err != nil {
-		s.l.Error("Cant create token", slog.String("error", err.Error()))
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, err
 	}
-
-	return &auth.LoginResponse{
-		Success: true,
-		Token:   &auth.Token{Token: token},
-	}, nil
+	// 2. Generate tokens or challenge if second factor is required
+	return s.finishLogin(ctx, u)
 }
 
 func (s *Service) LoginByEmail(ctx context.Context, req *auth.LoginRequestByEmail) (*auth.LoginResponse, error) {
-	// 1. Find user by username
-	u, err := s.st.FindUserByEmail(ctx, req.Email)
-	if err != nil {
-		s.l.Error("Cant find user by username", slog.String("error", err.Error()))
-		return nil, status.Error(codes.Internal, "internal error")
-	}
-	if u == nil {
-		return nil, status.Error(codes.NotFound, "user not found")
-	}
-	// 2. Compare password
-	if !u.ComparePassword(req.Password) {
-		return nil, status.Error(codes.Unauthenticated, "wrong password")
-	}
-	// 3. Generate token
-	token, err := s.tokenManager.CreateToken(u)
+	// 1. Find user by email and compare password (failed attempts are throttled)
+	u, err := s.login(ctx, req.Email, req.Password, s.st.FindUserByEmail)
 	if err != nil {
-		s.l.Error("Cant create token", slog.String("error", err.Error()))
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, err
 	}
-
-	return &auth.LoginResponse{
-		Success: true,
-		Token:   &auth.Token{Token: token},
-	}, nil
+	// 2. Generate tokens or challenge if second factor is required
+	return s.finishLogin(ctx, u)
 }
 
 func (s *Service) CreateUser(ctx context.Context, request *auth.CreateUserRequest) (*auth.CreateUserResponse, error) {
-	const tokenLength = 6
+	var v validation.Violations
+	v.Add("username", validation.Username(request.Username))
+	v.Add("email", validation.Email(request.Email))
+	v.Add("password", s.passwordPolicy.Validate(request.Password, request.Username, request.Email))
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	// 0. Check if user can sign up (e.g. has invitation)
+	inviteHash, err := s.checkRegistration(ctx, request.Email, request.InviteCode)
+	if err != nil {
+		return nil, err
+	}
 
 	// 1. Check if username or email already exists
 	u, err := s.st.FindUserByEmail(ctx, request.Email)
@@ -142,43 +356,42 @@ func (s *Service) CreateUser(ctx context.Context, request *auth.CreateUserReques
 	}
 	// 2. Create user
 	user := models.User{
-		Username: request.Username,
-		Password: request.Password,
-		Email:    request.Email,
+		Username:  request.Username,
+		Password:  request.Password,
+		Email:     request.Email,
+		CreatedAt: time.Now(),
 	}
 	// 3. Hash password
 	s.l.Debug("starting 3")
 
-	err = user.HashPassword()
+	err = user.HashPassword(s.passwords)
 	if err != nil {
 		s.l.Error("Cant hash password", slog.String("error", err.Error()))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
-	// 4. Save user
-	err = s.st.CreateUser(ctx, &user)
-	if err != nil {
-		s.l.Error("Cant create user", slog.String("error", err.Error()))
-		return nil, status.Error(codes.Internal, "internal error")
-	}
-
-	// 5. Create token for email confirm
-	s.l.Debug("starting 5")
-	s.l.Debug(fmt.Sprint(s.tokenManager == nil))
-	s.l.Debug(fmt.Sprint(u == nil))
-
-	token := email_token.GetRndEmailToken(tokenLength)
-
-	err = s.stEmailToken.CreateEmailToken(ctx, string(token), user.ID)
-	if err != nil {
-		s.l.Error("Cant save to storage token for email confirm", slog.String("error", err.Error()))
-		return nil, status.Error(codes.Internal, "internal error")
-	}
-
-	err = s.notificationService.SendEmailConfirmationEmail(ctx, string(token), user.Email)
+	// 4. Save user, invitation is used in the same transaction
+	if inviteHash != "" {
+		ok, err := s.stInvitation.CreateInvitedUser(ctx, &user, inviteHash)
+		if err != nil {
+			s.l.Error("Cant create invited user", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if !ok {
+			return nil, errInvalidInvitation
+		}
+	} else {
+		err = s.st.CreateUser(ctx, &user)
+		if err != nil {
+			s.l.Error("Cant create user", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	// 5. Create and send code for email confirm
+	err = s.sendVerificationCode(ctx, &user)
 	if err != nil {
-		s.l.Error("Cant use SendEmailConfirmationEmail (notifications service issue)", slog.String("error", err.Error()))
-		return nil, status.Error(codes.Internal, "internal error")
+		return nil, err
 	}
 
 	return &auth.CreateUserResponse{
@@ -187,64 +400,192 @@ func (s *Service) CreateUser(ctx context.Context, request *auth.CreateUserReques
 	}, nil
 }
 
+// UpdateUser changes only fields from update mask ("username", "email", "password"),
+// current password is required because all of them are used to login.
+// New email isn't set right away, it is pending until confirmed with ConfirmEmailChange.
 func (s *Service) UpdateUser(ctx context.Context, request *auth.UpdateUserRequest) (*auth.UpdateUserResponse, error) {
-	// 1. Get id from token
-	id, err := s.tokenManager.ParseToken(request.Token.Token)
-	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid token")
+	paths := request.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "update mask is empty")
 	}
-
-	user := models.User{
-		ID:       id,
-		Username: request.Username,
-		Password: request.Password,
-		Email:    request.Email,
+	for _, path := range paths {
+		switch path {
+		case updateFieldUsername, updateFieldEmail, updateFieldPassword:
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown field in update mask: %s", path)
+		}
 	}
 
-	// 2. Hash password
-	err = user.HashPassword()
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, request.Token)
 	if err != nil {
-		s.l.Error("Cant hash password", slog.String("error", err.Error()))
+		return nil, err
+	}
+	u, err := s.st.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
+	if u == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
 
-	err = s.st.UpdateUser(ctx, &user)
-	if err != nil {
+	// 2. Check current password
+	if ok, _ := u.ComparePassword(s.passwords, request.CurrentPassword); !ok {
+		return nil, status.Error(codes.PermissionDenied, "invalid current password")
+	}
+
+	// 3. Apply fields from mask
+	var v validation.Violations
+	var changed, passwordChanged bool
+	var pendingEmail string
+	for _, path := range paths {
+		switch path {
+		case updateFieldUsername:
+			if request.Username == u.Username {
+				continue
+			}
+			if err := validation.Username(request.Username); err != nil {
+				v.Add("username", err)
+				continue
+			}
+			other, err := s.st.FindUserByUsername(ctx, request.Username)
+			if err != nil {
+				s.l.Error("Cant find user by username", slog.String("error", err.Error()))
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+			// user can change case of own username
+			if other != nil && other.ID != u.ID {
+				return nil, status.Error(codes.AlreadyExists, "user with this username already exists")
+			}
+			u.Username = request.Username
+			changed = true
+		case updateFieldEmail:
+			if request.Email == u.Email {
+				continue
+			}
+			if err := validation.Email(request.Email); err != nil {
+				v.Add("email", err)
+				continue
+			}
+			other, err := s.st.FindUserByEmail(ctx, request.Email)
+			if err != nil {
+				s.l.Error("Cant find user by email", slog.String("error", err.Error()))
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+			if other != nil && other.ID != u.ID {
+				return nil, status.Error(codes.AlreadyExists, "user with this email already exists")
+			}
+			pendingEmail = request.Email
+		case updateFieldPassword:
+			if err := s.passwordPolicy.Validate(request.Password, u.Username, u.Email); err != nil {
+				v.Add("password", err)
+				continue
+			}
+			u.Password = request.Password
+			err = u.HashPassword(s.passwords)
+			if err != nil {
+				s.l.Error("Cant hash password", slog.String("error", err.Error()))
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+			changed = true
+			passwordChanged = true
+		}
+	}
+
+	if err := v.Err(); err != nil {
 		return nil, err
 	}
 
+	// 4. Save user
+	if changed {
+		err = s.st.UpdateUser(ctx, u)
+		if err != nil {
+			s.l.Error("Cant update user", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	// 5. Logout other devices, old password could be known to someone else
+	if passwordChanged {
+		s.audit(ctx, models.AuthEventPasswordChanged, u.ID, u.ID, "")
+
+		err = s.revokeOtherSessions(ctx, u.ID, claims.SessionID)
+		if err != nil {
+			s.l.Error("Cant revoke sessions", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	// 6. Send code to new email
+	if pendingEmail != "" {
+		err = s.requestEmailChange(ctx, u, pendingEmail)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &auth.UpdateUserResponse{
-		Success: true,
-		NewUser: user.ToAuthUser(),
+		Success:      true,
+		NewUser:      u.ToAuthUser(),
+		PendingEmail: pendingEmail,
 	}, nil
 }
 
+// DeleteUser marks user as pending deletion, user can be restored with RestoreUser
+// until grace period ends, after that user is purged (see PurgeDeletedUsersLoop)
 func (s *Service) DeleteUser(ctx context.Context, request *auth.DeleteUserRequest) (*auth.DeleteUserResponse, error) {
 	// 1. Get id from token
-	id, err := s.tokenManager.ParseToken(request.Token.Token)
+	claims, err := s.parseToken(ctx, request.Token)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid token")
+		return nil, err
 	}
-	// 2. Delete user by id
-	err = s.st.DeleteUser(ctx, id)
+	id := claims.Subject
+
+	// 2. Mark user (and bots of the user) as deleted
+	now := time.Now()
+	err = s.st.SetUserDeleted(ctx, id, &now)
 	if err != nil {
 		s.l.Error("Cant delete user", slog.String("error", err.Error()))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
+	s.audit(ctx, models.AuthEventAccountDeleted, id, id, "")
+
+	// 3. Logout everywhere
+	err = s.revokeAllSessions(ctx, id)
+	if err != nil {
+		s.l.Error("Cant revoke sessions", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
 
 	return &auth.DeleteUserResponse{
 		Success: true,
+		PurgeAt: now.Add(s.cfg.AccountDeletion.GracePeriod).Unix(),
 	}, nil
 }
 
+// GetUserByToken accepts jwt tokens and api keys, scopes in response are set only for api keys
+// (jwt tokens aren't limited)
 func (s *Service) GetUserByToken(ctx context.Context, request *auth.GetUserByTokenRequest) (*auth.GetUserResponse, error) {
-	// 1. Parse token
-	id, err := s.tokenManager.ParseToken(request.Token.Token)
+	if api_key.IsAPIKey(string(request.Token.GetToken())) {
+		u, scopes, err := s.authenticateAPIKey(ctx, string(request.Token.GetToken()))
+		if err != nil {
+			return nil, err
+		}
+		return &auth.GetUserResponse{
+			Success: true,
+			User:    u.ToAuthUser(),
+			Scopes:  scopes,
+		}, nil
+	}
+
+	// 1. Parse token (revoked tokens and tokens of revoked sessions are rejected here)
+	claims, err := s.parseToken(ctx, request.Token)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid token")
+		return nil, err
 	}
 	// 2. Find user by id
-	u, err := s.st.GetUserByID(ctx, id)
+	u, err := s.st.GetUserByID(ctx, claims.Subject)
 	if err != nil {
 		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
 		return nil, status.Error(codes.Internal, "internal error")
@@ -252,6 +593,9 @@ func (s *Service) GetUserByToken(ctx context.Context, request *auth.GetUserByTok
 	if u == nil {
 		return nil, status.Error(codes.NotFound, "user not found")
 	}
+	if err := checkActive(u); err != nil {
+		return nil, err
+	}
 	return &auth.GetUserResponse{
 		Success: true,
 		User:    u.ToAuthUser(),
@@ -275,6 +619,58 @@ func (s *Service) GetUserByEmail(ctx context.Context, request *auth.GetUserByEma
 
 }
 
+// GetUsersByIDs is batch lookup for other services (e.g. to show room members),
+// unknown ids are skipped, so callers must handle missing (purged) users.
+// Users are requested on behalf of token owner, emails are returned only to admins and to users themselves.
+func (s *Service) GetUsersByIDs(ctx context.Context, request *auth.GetUsersByIDsRequest) (*auth.GetUsersByIDsResponse, error) {
+	if len(request.Ids) > maxUsersBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "too many ids, max is %d", maxUsersBatch)
+	}
+
+	// 1. Get user from token
+	caller, err := s.tokenCaller(ctx, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.Ids) == 0 {
+		return &auth.GetUsersByIDsResponse{Success: true}, nil
+	}
+
+	// 2. Find users
+	users, err := s.st.GetUsersByIDs(ctx, request.Ids)
+	if err != nil {
+		s.l.Error("Cant get users by ids", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	isAdmin := caller.HasRole(models.RoleAdmin)
+	resp := &auth.GetUsersByIDsResponse{
+		Success: true,
+		Users:   make([]*auth.User, 0, len(users)),
+	}
+	for i := range users {
+		u := users[i].ToAuthUser()
+		if !isAdmin && users[i].ID != caller.ID {
+			u.Email = ""
+		}
+		resp.Users = append(resp.Users, u)
+	}
+
+	return resp, nil
+}
+
+// tokenCaller returns active user token or api key belongs to.
+// Returned error is already grpc status.
+func (s *Service) tokenCaller(ctx context.Context, t *auth.Token) (*models.User, error) {
+	if api_key.IsAPIKey(string(t.GetToken())) {
+		u, _, err := s.authenticateAPIKey(ctx, string(t.GetToken()))
+		return u, err
+	}
+
+	return s.activeCaller(ctx, t)
+}
+
 func (s *Service) GetUserByUsername(ctx context.Context, request *auth.GetUserByUsernameRequest) (*auth.GetUserResponse, error) {
 	// 1. Find user by username
 	u, err := s.st.FindUserByUsername(ctx, request.Username)
@@ -292,25 +688,43 @@ func (s *Service) GetUserByUsername(ctx context.Context, request *auth.GetUserBy
 }
 
 func (s *Service) VerifyUser(ctx context.Context, req *auth.VerifyUserRequest) (*auth.VerifyUserResponse, error) {
-	// 1. Find userID using token
-	userID, err := s.stEmailToken.GetUserIDByToken(ctx, req.VerificationCode)
+	// 1. Find user by email
+	u, err := s.st.FindUserByEmail(ctx, req.Email)
 	if err != nil {
-		s.l.Error("Cant get userID by email verification code", slog.String("error", err.Error()))
+		s.l.Error("Cant find user by email", slog.String("error", err.Error()))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
+	if u == nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+	}
+	if u.ConfirmedEmail {
+		return nil, status.Error(codes.FailedPrecondition, "email already verified")
+	}
 
-	// 2. Find user by userID
-	u, err := s.st.GetUserByID(ctx, userID)
+	// 2. Find code of the user
+	t, err := s.stEmailToken.GetEmailToken(ctx, u.ID)
 	if err != nil {
-		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		s.l.Error("Cant get email verification code", slog.String("error", err.Error()))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
+	if t == nil || time.Now().After(t.ExpiresAt) {
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
+	}
+	if t.Attempts >= s.cfg.EmailVerification.MaxAttempts {
+		return nil, status.Error(codes.ResourceExhausted, "too many attempts, request new code")
+	}
 
-	if u == nil {
-		return nil, status.Error(codes.NotFound, "user not found")
+	// 3. Compare codes, every wrong guess is counted
+	if subtle.ConstantTimeCompare([]byte(email_token.Hash(req.VerificationCode)), []byte(t.CodeHash)) != 1 {
+		err = s.stEmailToken.IncrementEmailTokenAttempts(ctx, t.ID)
+		if err != nil {
+			s.l.Error("Cant increment email verification attempts", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, status.Error(codes.InvalidArgument, "invalid or expired code")
 	}
 
-	// 3. Update user
+	// 4. Update user
 	u.ConfirmedEmail = true
 	err = s.st.UpdateUser(ctx, u)
 	if err != nil {
@@ -318,13 +732,15 @@ func (s *Service) VerifyUser(ctx context.Context, req *auth.VerifyUserRequest) (
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
-	// 4. Delete token
-	err = s.stEmailToken.DeleteEmailToken(ctx, req.VerificationCode)
+	// 5. Delete code
+	err = s.stEmailToken.DeleteEmailTokens(ctx, u.ID)
 	if err != nil {
 		s.l.Error("Cant delete email verification code from db", slog.String("error", err.Error()))
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
+	s.audit(ctx, models.AuthEventEmailVerified, "", u.ID, u.Email)
+
 	return &auth.VerifyUserResponse{
 		Success: true,
 		User:    u.ToAuthUser(),
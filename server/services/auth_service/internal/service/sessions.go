@@ -0,0 +1,113 @@
+package service
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Service) ListSessions(ctx context.Context, req *auth.ListSessionsRequest) (*auth.ListSessionsResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Find sessions, sessions that weren't used longer than refresh token lives are expired
+	sessions, err := s.stSession.ListUserSessions(ctx, claims.Subject, time.Now().Add(-s.cfg.Tokens.RefreshTokenTTL))
+	if err != nil {
+		s.l.Error("Cant list sessions", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &auth.ListSessionsResponse{
+		Success:  true,
+		Sessions: make([]*auth.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &auth.Session{
+			Id:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			Ip:         session.IP,
+			CreatedAt:  session.CreatedAt.Unix(),
+			LastSeenAt: session.LastSeenAt.Unix(),
+			Current:    session.ID == claims.SessionID,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *Service) RevokeSession(ctx context.Context, req *auth.RevokeSessionRequest) (*auth.RevokeSessionResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Find session
+	session, err := s.stSession.GetSession(ctx, req.SessionId)
+	if err != nil {
+		s.l.Error("Cant get session", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if session == nil || session.UserID != claims.Subject {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+
+	// 3. Revoke session, its access tokens are rejected by GetUserByToken after this
+	err = s.stSession.RevokeSession(ctx, session.ID)
+	if err != nil {
+		s.l.Error("Cant revoke session", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.RevokeSessionResponse{
+		Success: true,
+	}, nil
+}
+
+// newSession creates session for new login using info about client from ctx
+func (s *Service) newSession(ctx context.Context, userID string) (*models.Session, error) {
+	deviceName, userAgent := clientDevice(ctx)
+
+	session := &models.Session{
+		UserID:     userID,
+		DeviceName: deviceName,
+		UserAgent:  userAgent,
+		IP:         clientIP(ctx),
+	}
+
+	err := s.stSession.CreateSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// revokeOtherSessions revokes all sessions of the user except current one
+func (s *Service) revokeOtherSessions(ctx context.Context, userID, currentSessionID string) error {
+	sessions, err := s.stSession.ListUserSessions(ctx, userID, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+		err = s.stSession.RevokeSession(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
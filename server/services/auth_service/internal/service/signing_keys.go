@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+)
+
+// GetSigningKeys returns public keys (JWKS-style) so other services can verify tokens locally.
+// If tokens are signed with symmetric key, list is empty.
+func (s *Service) GetSigningKeys(_ context.Context, _ *auth.GetSigningKeysRequest) (*auth.GetSigningKeysResponse, error) {
+	keys := s.tokenManager.PublicKeys()
+
+	resp := make([]*auth.SigningKey, 0, len(keys))
+	for _, k := range keys {
+		resp = append(resp, &auth.SigningKey{
+			Kid: k.Kid,
+			Kty: k.Kty,
+			Alg: k.Alg,
+			Use: k.Use,
+			Crv: k.Crv,
+			X:   k.X,
+			N:   k.N,
+			E:   k.E,
+		})
+	}
+
+	return &auth.GetSigningKeysResponse{
+		Keys: resp,
+	}, nil
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"auth_service/internal/lib/email_token"
+	"auth_service/internal/lib/refresh_token"
+	"auth_service/internal/lib/totp"
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Service) EnrollTOTP(ctx context.Context, req *auth.EnrollTOTPRequest) (*auth.EnrollTOTPResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Check if 2FA is already enabled
+	t, err := s.stTwoFactor.GetTOTP(ctx, claims.Subject)
+	if err != nil {
+		s.l.Error("Cant get totp", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if t != nil && t.Enabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	// 3. Create secret, it works only after confirmation
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.l.Error("Cant generate totp secret", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.stTwoFactor.SaveTOTPSecret(ctx, claims.Subject, secret)
+	if err != nil {
+		s.l.Error("Cant save totp secret", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.EnrollTOTPResponse{
+		Success: true,
+		Secret:  secret,
+		Uri:     totp.URI(s.cfg.TwoFactor.Issuer, claims.Email, secret),
+	}, nil
+}
+
+func (s *Service) ConfirmTOTP(ctx context.Context, req *auth.ConfirmTOTPRequest) (*auth.ConfirmTOTPResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Find pending secret
+	t, err := s.stTwoFactor.GetTOTP(ctx, claims.Subject)
+	if err != nil {
+		s.l.Error("Cant get totp", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if t == nil {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication enrolment wasn't started")
+	}
+	if t.Enabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	// 3. Check first code
+	step, ok := totp.Validate(t.Secret, req.Code, time.Now())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	// 4. Create recovery codes, user sees them only once
+	recoveryCodes := make([]string, s.cfg.TwoFactor.RecoveryCodes)
+	hashes := make([]string, len(recoveryCodes))
+	for i := range recoveryCodes {
+		recoveryCodes[i], err = totp.GenerateRecoveryCode()
+		if err != nil {
+			s.l.Error("Cant generate recovery code", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		hashes[i] = email_token.Hash(recoveryCodes[i])
+	}
+
+	// 5. Enable
+	err = s.stTwoFactor.EnableTOTP(ctx, claims.Subject, step, hashes)
+	if err != nil {
+		s.l.Error("Cant enable totp", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.ConfirmTOTPResponse{
+		Success:       true,
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (s *Service) DisableTOTP(ctx context.Context, req *auth.DisableTOTPRequest) (*auth.DisableTOTPResponse, error) {
+	// 1. Get user from token
+	u, err := s.activeCaller(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Check code (totp or recovery), wrong codes are counted as failed logins
+	ok, err := s.checkSecondFactorThrottled(ctx, u, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	// 3. Disable
+	err = s.stTwoFactor.DisableTOTP(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant disable totp", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.DisableTOTPResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *Service) CompleteLogin(ctx context.Context, req *auth.CompleteLoginRequest) (*auth.LoginResponse, error) {
+	// 1. Find challenge
+	c, err := s.stTwoFactor.GetLoginChallengeByHash(ctx, refresh_token.Hash(req.Challenge))
+	if err != nil {
+		s.l.Error("Cant get login challenge", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if c == nil || c.Used || time.Now().After(c.ExpiresAt) || c.Attempts >= s.cfg.TwoFactor.MaxAttempts {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired challenge")
+	}
+
+	u, err := s.st.GetUserByID(ctx, c.UserID)
+	if err != nil {
+		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired challenge")
+	}
+
+	// 2. Check code (totp or recovery), wrong codes are counted as failed logins too
+	ok, err := s.checkSecondFactorThrottled(ctx, u, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		err = s.stTwoFactor.IncrementLoginChallengeAttempts(ctx, c.ID)
+		if err != nil {
+			s.l.Error("Cant increment login challenge attempts", slog.String("error", err.Error()))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid code")
+	}
+
+	// 3. Mark challenge as used
+	ok, err = s.stTwoFactor.MarkLoginChallengeUsed(ctx, c.ID)
+	if err != nil {
+		s.l.Error("Cant mark login challenge as used", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired challenge")
+	}
+
+	// 4. Generate access and refresh tokens (new token family)
+	return s.issueLoginTokens(ctx, u)
+}
+
+// finishLogin is called after user is found and password is checked.
+// It returns challenge if user has 2FA enabled and tokens otherwise.
+func (s *Service) finishLogin(ctx context.Context, u *models.User) (*auth.LoginResponse, error) {
+	if err := checkActive(u); err != nil {
+		return nil, err
+	}
+
+	t, err := s.stTwoFactor.GetTOTP(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant get totp", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if t == nil || !t.Enabled {
+		return s.issueLoginTokens(ctx, u)
+	}
+
+	challenge, hash, err := refresh_token.Generate()
+	if err != nil {
+		s.l.Error("Cant generate login challenge", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.stTwoFactor.CreateLoginChallenge(ctx, u.ID, hash, time.Now().Add(s.cfg.TwoFactor.ChallengeTTL))
+	if err != nil {
+		s.l.Error("Cant save login challenge", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.LoginResponse{
+		Success:              true,
+		SecondFactorRequired: true,
+		Challenge:            challenge,
+	}, nil
+}
+
+func (s *Service) issueLoginTokens(ctx context.Context, u *models.User) (*auth.LoginResponse, error) {
+	if err := checkActive(u); err != nil {
+		return nil, err
+	}
+
+	err := s.resetLoginFailures(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	token, refreshToken, err := s.issueTokens(ctx, u, "")
+	if err != nil {
+		s.l.Error("Cant create tokens", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	s.audit(ctx, models.AuthEventLoginSucceeded, u.ID, u.ID, "")
+
+	return &auth.LoginResponse{
+		Success:      true,
+		Token:        &auth.Token{Token: token},
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// checkSecondFactorThrottled is checkSecondFactor for account which isn't blocked, wrong
+// code is registered as failed login, so codes can't be guessed with any rpc that accepts them.
+// Returned error is already grpc status.
+func (s *Service) checkSecondFactorThrottled(ctx context.Context, u *models.User, code string) (bool, error) {
+	blocked, err := s.isLoginBlocked(ctx, accountThrottlePrefix+u.ID)
+	if err != nil {
+		return false, err
+	}
+	if blocked {
+		return false, errTooManyAttempts
+	}
+
+	ok, err := s.checkSecondFactor(ctx, u.ID, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	err = s.registerLoginFailure(ctx, u, accountThrottlePrefix+u.ID, clientIP(ctx), "invalid second factor")
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// checkSecondFactor checks totp code or recovery code of the user,
+// every code can be used only once. Returned error is already grpc status.
+func (s *Service) checkSecondFactor(ctx context.Context, userID, code string) (bool, error) {
+	t, err := s.stTwoFactor.GetTOTP(ctx, userID)
+	if err != nil {
+		s.l.Error("Cant get totp", slog.String("error", err.Error()))
+		return false, status.Error(codes.Internal, "internal error")
+	}
+	if t == nil || !t.Enabled {
+		return false, status.Error(codes.FailedPrecondition, "two-factor authentication isn't enabled")
+	}
+
+	if step, ok := totp.Validate(t.Secret, code, time.Now()); ok {
+		ok, err = s.stTwoFactor.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			s.l.Error("Cant save totp step", slog.String("error", err.Error()))
+			return false, status.Error(codes.Internal, "internal error")
+		}
+		return ok, nil
+	}
+
+	ok, err := s.stTwoFactor.UseRecoveryCode(ctx, userID, email_token.Hash(totp.NormalizeRecoveryCode(code)))
+	if err != nil {
+		s.l.Error("Cant use recovery code", slog.String("error", err.Error()))
+		return false, status.Error(codes.Internal, "internal error")
+	}
+	return ok, nil
+}
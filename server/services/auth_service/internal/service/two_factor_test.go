@@ -0,0 +1,111 @@
+package service
+
+import (
+	"auth_service/internal/config"
+	"auth_service/internal/models"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// memLoginThrottle is in-memory LoginThrottleStorage
+type memLoginThrottle struct {
+	throttles map[string]*models.LoginThrottle
+}
+
+func (m *memLoginThrottle) GetLoginThrottle(_ context.Context, key string) (*models.LoginThrottle, error) {
+	return m.throttles[key], nil
+}
+
+func (m *memLoginThrottle) RegisterLoginFailure(_ context.Context, key string, windowStart time.Time) (*models.LoginThrottle, error) {
+	t := m.throttles[key]
+	if t == nil || t.LastFailureAt.Before(windowStart) {
+		t = &models.LoginThrottle{Key: key}
+		m.throttles[key] = t
+	}
+	t.Failures++
+	t.LastFailureAt = time.Now()
+	return t, nil
+}
+
+func (m *memLoginThrottle) BlockLogin(_ context.Context, key string, until time.Time) error {
+	m.throttles[key].BlockedUntil = &until
+	return nil
+}
+
+func (m *memLoginThrottle) ResetLoginFailures(_ context.Context, key string) error {
+	delete(m.throttles, key)
+	return nil
+}
+
+func (m *memLoginThrottle) DeleteExpiredLoginThrottles(context.Context, time.Time, time.Time) (int64, error) {
+	return 0, nil
+}
+
+// memTwoFactor has enabled totp without recovery codes, so every code except current totp is wrong
+type memTwoFactor struct {
+	TwoFactorStorage
+	checked int
+}
+
+func (m *memTwoFactor) GetTOTP(_ context.Context, userID string) (*models.TOTP, error) {
+	m.checked++
+	return &models.TOTP{UserID: userID, Secret: "JBSWY3DPEHPK3PXP", Enabled: true}, nil
+}
+
+func (m *memTwoFactor) UseRecoveryCode(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+type nopAuthEvents struct {
+	AuthEventStorage
+}
+
+func (nopAuthEvents) CreateAuthEvent(context.Context, *models.AuthEvent) error {
+	return nil
+}
+
+func TestCheckSecondFactorThrottled(t *testing.T) {
+	twoFactor := &memTwoFactor{}
+	s := &Service{
+		stLoginThrottle: &memLoginThrottle{throttles: map[string]*models.LoginThrottle{}},
+		stTwoFactor:     twoFactor,
+		stAuthEvent:     nopAuthEvents{},
+		cfg: &config.Config{LoginThrottle: config.LoginThrottle{
+			Window:          time.Hour,
+			BackoffAfter:    3,
+			BaseDelay:       time.Minute,
+			MaxDelay:        time.Hour,
+			LockoutAfter:    10,
+			LockoutDuration: time.Hour,
+		}},
+		l: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	u := &models.User{ID: "user-1"}
+	ctx := context.Background()
+
+	// wrong codes are rejected until account is blocked
+	for i := 0; i < 3; i++ {
+		ok, err := s.checkSecondFactorThrottled(ctx, u, "not-a-code")
+		if err != nil {
+			t.Fatalf("attempt %d: %v", i+1, err)
+		}
+		if ok {
+			t.Fatalf("attempt %d: wrong code accepted", i+1)
+		}
+	}
+
+	// after that code isn't checked at all
+	_, err := s.checkSecondFactorThrottled(ctx, u, "not-a-code")
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("got %v, want ResourceExhausted", err)
+	}
+	if twoFactor.checked != 3 {
+		t.Fatalf("code checked %d times, want 3", twoFactor.checked)
+	}
+}
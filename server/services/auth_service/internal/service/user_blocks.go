@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBlockRelationsBatch is max number of ids in GetBlockRelations
+const maxBlockRelationsBatch = 1000
+
+// BlockUser hides messages of the user from the caller and forbids invites between them
+func (s *Service) BlockUser(ctx context.Context, req *auth.BlockUserRequest) (*auth.BlockUserResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserId == claims.Subject {
+		return nil, status.Error(codes.InvalidArgument, "cant block yourself")
+	}
+
+	// 2. Find user to block
+	u, err := s.st.GetUserByID(ctx, req.UserId)
+	if err != nil {
+		s.l.Error("Cant get user by id", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if u == nil {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	// 3. Block
+	err = s.stUserBlock.CreateUserBlock(ctx, claims.Subject, u.ID)
+	if err != nil {
+		s.l.Error("Cant block user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.BlockUserResponse{Success: true}, nil
+}
+
+func (s *Service) UnblockUser(ctx context.Context, req *auth.UnblockUserRequest) (*auth.UnblockUserResponse, error) {
+	// 1. Get user from token
+	claims, err := s.parseToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Unblock
+	ok, err := s.stUserBlock.DeleteUserBlock(ctx, claims.Subject, req.UserId)
+	if err != nil {
+		s.l.Error("Cant unblock user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	if !ok {
+		return nil, status.Error(codes.NotFound, "user isn't blocked")
+	}
+
+	return &auth.UnblockUserResponse{Success: true}, nil
+}
+
+// ListBlocked returns users blocked by the caller, most recently blocked first.
+// Api keys are accepted too, chat service uses it to hide messages from bots' streams.
+func (s *Service) ListBlocked(ctx context.Context, req *auth.ListBlockedRequest) (*auth.ListBlockedResponse, error) {
+	// 1. Get user from token
+	caller, err := s.tokenCaller(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Find blocked users
+	blocks, err := s.stUserBlock.ListUserBlocks(ctx, caller.ID)
+	if err != nil {
+		s.l.Error("Cant list blocked users", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &auth.ListBlockedResponse{
+		Success: true,
+		Users:   make([]*auth.BlockedUser, 0, len(blocks)),
+	}
+	for i := range blocks {
+		resp.Users = append(resp.Users, blocks[i].ToAuthBlockedUser())
+	}
+
+	return resp, nil
+}
+
+// GetBlockRelations is lookup for other services (e.g. room invites),
+// it returns which of other users blocked the user and which of them the user blocked.
+// Only the user can get their relations, so it can't be used to find out who blocked whom.
+func (s *Service) GetBlockRelations(ctx context.Context, req *auth.GetBlockRelationsRequest) (*auth.GetBlockRelationsResponse, error) {
+	if len(req.OtherUserIds) > maxBlockRelationsBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "too many ids, max is %d", maxBlockRelationsBatch)
+	}
+
+	// 1. Get user from token
+	caller, err := s.tokenCaller(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserId != caller.ID {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	if len(req.OtherUserIds) == 0 {
+		return &auth.GetBlockRelationsResponse{Success: true}, nil
+	}
+
+	// 2. Find blocks in both directions
+	blockedBy, blocked, err := s.stUserBlock.GetBlockRelations(ctx, req.UserId, req.OtherUserIds)
+	if err != nil {
+		s.l.Error("Cant get block relations", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &auth.GetBlockRelationsResponse{
+		Success:   true,
+		BlockedBy: blockedBy,
+		Blocked:   blocked,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 100
+	maxSearchQueryLength  = 64
+	// maxSearchOffset limits paging deep into results, query should be refined instead
+	maxSearchOffset = 1000
+)
+
+// SearchUsers finds users by prefix or similarity of username and display name (e.g. to invite them to room).
+// Emails are returned only to admins, suspended users are shown only to moderators and admins.
+func (s *Service) SearchUsers(ctx context.Context, req *auth.SearchUsersRequest) (*auth.SearchUsersResponse, error) {
+	// 1. Get caller from token
+	caller, err := s.activeCaller(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Validate query and page
+	q := strings.TrimSpace(req.Query)
+	if q == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	if utf8.RuneCountInString(q) > maxSearchQueryLength {
+		return nil, status.Errorf(codes.InvalidArgument, "query is too long, max is %d characters", maxSearchQueryLength)
+	}
+	limit := int(req.PageSize)
+	if limit <= 0 {
+		limit = defaultSearchPageSize
+	}
+	if limit > maxSearchPageSize {
+		limit = maxSearchPageSize
+	}
+	offset := 0
+	if req.PageToken != "" {
+		offset, err = strconv.Atoi(req.PageToken)
+		if err != nil || offset < 0 || offset > maxSearchOffset {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+
+	// 3. Search, one more user is requested to know if there is next page
+	results, err := s.st.SearchUsers(ctx, models.UserSearch{
+		Query:            q,
+		IncludeSuspended: caller.HasRole(models.RoleModerator),
+		Offset:           offset,
+		Limit:            limit + 1,
+	})
+	if err != nil {
+		s.l.Error("Cant search users", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &auth.SearchUsersResponse{Success: true}
+	if len(results) > limit && offset+limit <= maxSearchOffset {
+		resp.NextPageToken = strconv.Itoa(offset + limit)
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	showEmail := caller.HasRole(models.RoleAdmin)
+	resp.Results = make([]*auth.UserSearchResult, 0, len(results))
+	for i := range results {
+		u := results[i].ToAuthUser()
+		if !showEmail {
+			u.Email = ""
+		}
+
+		displayName := results[i].DisplayName
+		if displayName == "" {
+			displayName = results[i].Username
+		}
+
+		resp.Results = append(resp.Results, &auth.UserSearchResult{
+			User:        u,
+			DisplayName: displayName,
+			AvatarRef:   results[i].AvatarRef,
+		})
+	}
+
+	return resp, nil
+}
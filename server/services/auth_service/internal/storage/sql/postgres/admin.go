@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (s *Storage) ListUsers(ctx context.Context, filter models.UserFilter) ([]models.User, error) {
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Query != "" {
+		// escape LIKE wildcards so query is matched literally
+		q := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(filter.Query) + "%"
+		p := arg(q)
+		where = append(where, fmt.Sprintf("(username ILIKE %s OR email ILIKE %s)", p, p))
+	}
+	if filter.Role != "" {
+		where = append(where, "role = "+arg(filter.Role))
+	}
+	if filter.SuspendedOnly {
+		where = append(where, "suspended_at IS NOT NULL")
+	}
+	if filter.AfterID != "" {
+		where = append(where, "id > "+arg(filter.AfterID))
+	}
+
+	query := `SELECT * FROM users`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id LIMIT " + arg(filter.Limit)
+
+	var users []models.User
+	err := s.db.SelectContext(ctx, &users, query, args...)
+	return users, err
+}
+
+// SetUserSuspended suspends user if suspendedAt isn't nil, otherwise unsuspends
+func (s *Storage) SetUserSuspended(ctx context.Context, id string, suspendedAt *time.Time, reason string) error {
+	query := `UPDATE users SET suspended_at = $2, suspend_reason = $3 WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id, suspendedAt, reason)
+	return err
+}
+
+func (s *Storage) SetUserRole(ctx context.Context, id, role string) error {
+	query := `UPDATE users SET role = $2 WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id, role)
+	return err
+}
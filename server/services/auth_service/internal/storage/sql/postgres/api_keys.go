@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	// create id
+	key.ID = uuid.New().String()
+
+	query := `
+INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes)
+VALUES (:id, :user_id, :name, :prefix, :key_hash, :scopes)`
+	_, err := s.db.NamedExecContext(ctx, query, key)
+	return err
+}
+
+func (s *Storage) GetAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	query := `SELECT * FROM api_keys WHERE id = $1`
+	var key models.APIKey
+	err := s.db.GetContext(ctx, &key, query, id)
+	if err != nil {
+		// if here is no key it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *Storage) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	query := `SELECT * FROM api_keys WHERE key_hash = $1`
+	var key models.APIKey
+	err := s.db.GetContext(ctx, &key, query, hash)
+	if err != nil {
+		// if here is no key it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *Storage) ListAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error) {
+	query := `SELECT * FROM api_keys WHERE user_id = $1 AND revoked = FALSE ORDER BY created_at DESC`
+	var keys []models.APIKey
+	err := s.db.SelectContext(ctx, &keys, query, userID)
+	return keys, err
+}
+
+// TouchAPIKey updates last usage time of the key, but not more often than once a minute
+func (s *Storage) TouchAPIKey(ctx context.Context, id string) error {
+	now := time.Now()
+	query := `
+UPDATE api_keys SET last_used_at = $2
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)`
+	_, err := s.db.ExecContext(ctx, query, id, now, now.Add(-time.Minute))
+	return err
+}
+
+func (s *Storage) RevokeAPIKey(ctx context.Context, id string) error {
+	query := `UPDATE api_keys SET revoked = TRUE WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
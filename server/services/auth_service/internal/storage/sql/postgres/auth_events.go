@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CreateAuthEvent appends event to audit log, events can't be changed after that
+func (s *Storage) CreateAuthEvent(ctx context.Context, event *models.AuthEvent) error {
+	query := `
+INSERT INTO auth_events (type, actor_id, target_id, ip, user_agent, details)
+VALUES (:type, :actor_id, :target_id, :ip, :user_agent, :details)`
+	_, err := s.db.NamedExecContext(ctx, query, event)
+	return err
+}
+
+func (s *Storage) ListAuthEvents(ctx context.Context, filter models.AuthEventFilter) ([]models.AuthEvent, error) {
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Type != "" {
+		where = append(where, "type = "+arg(filter.Type))
+	}
+	if filter.ActorID != "" {
+		where = append(where, "actor_id = "+arg(filter.ActorID))
+	}
+	if filter.TargetID != "" {
+		where = append(where, "target_id = "+arg(filter.TargetID))
+	}
+	if filter.IP != "" {
+		where = append(where, "ip = "+arg(filter.IP))
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= "+arg(filter.Since))
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "created_at < "+arg(filter.Until))
+	}
+	if filter.BeforeID != 0 {
+		where = append(where, "id < "+arg(filter.BeforeID))
+	}
+
+	query := `SELECT * FROM auth_events`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT " + arg(filter.Limit)
+
+	var events []models.AuthEvent
+	err := s.db.SelectContext(ctx, &events, query, args...)
+	return events, err
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateDataExport(ctx context.Context, export *models.DataExport) error {
+	// create id
+	export.ID = uuid.New().String()
+
+	query := `
+INSERT INTO data_exports (id, user_id, status, expires_at)
+VALUES (:id, :user_id, :status, :expires_at)`
+	_, err := s.db.NamedExecContext(ctx, query, export)
+	return err
+}
+
+func (s *Storage) GetDataExport(ctx context.Context, id string) (*models.DataExport, error) {
+	query := `SELECT * FROM data_exports WHERE id = $1`
+	var export models.DataExport
+	err := s.db.GetContext(ctx, &export, query, id)
+	if err != nil {
+		// if here is no export it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GetLatestDataExport returns last export requested by the user
+func (s *Storage) GetLatestDataExport(ctx context.Context, userID string) (*models.DataExport, error) {
+	query := `SELECT * FROM data_exports WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`
+	var export models.DataExport
+	err := s.db.GetContext(ctx, &export, query, userID)
+	if err != nil {
+		// if here is no export it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// FinishDataExport sets status of export, file path and code are empty for failed exports
+func (s *Storage) FinishDataExport(ctx context.Context, export *models.DataExport) error {
+	query := `
+UPDATE data_exports SET status = :status, file_path = :file_path, code_hash = :code_hash, expires_at = :expires_at
+WHERE id = :id`
+	_, err := s.db.NamedExecContext(ctx, query, export)
+	return err
+}
+
+func (s *Storage) IncrementDataExportAttempts(ctx context.Context, id string) error {
+	query := `UPDATE data_exports SET attempts = attempts + 1 WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// DeleteExpiredDataExports deletes exports expired before given time and returns them,
+// so their files can be removed
+func (s *Storage) DeleteExpiredDataExports(ctx context.Context, before time.Time) ([]models.DataExport, error) {
+	query := `DELETE FROM data_exports WHERE expires_at < $1 RETURNING *`
+	var exports []models.DataExport
+	err := s.db.SelectContext(ctx, &exports, query, before)
+	return exports, err
+}
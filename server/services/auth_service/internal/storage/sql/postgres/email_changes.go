@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// CreateEmailChange saves pending email of the user, previous pending email is replaced
+func (s *Storage) CreateEmailChange(ctx context.Context, change *models.EmailChange) error {
+	query := `
+INSERT INTO email_changes (user_id, new_email, code_hash, expires_at)
+VALUES (:user_id, :new_email, :code_hash, :expires_at)
+ON CONFLICT (user_id) DO UPDATE SET
+	new_email = EXCLUDED.new_email,
+	code_hash = EXCLUDED.code_hash,
+	attempts = 0,
+	expires_at = EXCLUDED.expires_at,
+	created_at = CURRENT_TIMESTAMP`
+	_, err := s.db.NamedExecContext(ctx, query, change)
+	return err
+}
+
+func (s *Storage) GetEmailChange(ctx context.Context, userID string) (*models.EmailChange, error) {
+	query := `SELECT * FROM email_changes WHERE user_id = $1`
+	var change models.EmailChange
+	err := s.db.GetContext(ctx, &change, query, userID)
+	if err != nil {
+		// if here is no pending email it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (s *Storage) IncrementEmailChangeAttempts(ctx context.Context, userID string) error {
+	query := `UPDATE email_changes SET attempts = attempts + 1 WHERE user_id = $1`
+	_, err := s.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+// ApplyEmailChange sets confirmed pending email as email of the user and removes it from pending
+func (s *Storage) ApplyEmailChange(ctx context.Context, userID, newEmail string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// new email isn't used by other account, so user isn't colliding anymore
+	query := `UPDATE users SET email = $1, email_canonical = $2, email_collision = FALSE, confirmed_email = TRUE WHERE id = $3`
+	_, err = tx.ExecContext(ctx, query, newEmail, models.CanonicalEmail(newEmail), userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM email_changes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
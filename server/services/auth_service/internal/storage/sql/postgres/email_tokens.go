@@ -1,24 +1,67 @@
 package postgres
 
-import "context"
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// CreateEmailToken saves new email confirmation code, previous codes of the user are removed
+// so only the latest sent code can be used
+func (s *Storage) CreateEmailToken(ctx context.Context, userID, codeHash string, expiresAt time.Time) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM email_confirm_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
 
-func (s *Storage) CreateEmailToken(ctx context.Context, token, userID string) error {
 	query := `
-INSERT INTO email_confirm_tokens (token, user_id)
-VALUES ($1, $2)`
-	_, err := s.db.ExecContext(ctx, query, token, userID)
-	return err
+INSERT INTO email_confirm_tokens (user_id, code_hash, expires_at)
+VALUES ($1, $2, $3)`
+	_, err = tx.ExecContext(ctx, query, userID, codeHash, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (s *Storage) GetUserIDByToken(ctx context.Context, token string) (string, error) {
-	query := `SELECT user_id FROM email_confirm_tokens WHERE token = $1`
-	var userID string
-	err := s.db.GetContext(ctx, &userID, query, token)
-	return userID, err
+// GetEmailToken returns the latest email confirmation code of the user
+func (s *Storage) GetEmailToken(ctx context.Context, userID string) (*models.EmailToken, error) {
+	query := `
+SELECT * FROM email_confirm_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1`
+	var t models.EmailToken
+	err := s.db.GetContext(ctx, &t, query, userID)
+	if err != nil {
+		// if here is no code it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *Storage) IncrementEmailTokenAttempts(ctx context.Context, id int) error {
+	query := `UPDATE email_confirm_tokens SET attempts = attempts + 1 WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
 }
 
-func (s *Storage) DeleteEmailToken(ctx context.Context, token string) error {
-	query := `DELETE FROM email_confirm_tokens WHERE token = $1`
-	_, err := s.db.ExecContext(ctx, query, token)
+func (s *Storage) DeleteEmailTokens(ctx context.Context, userID string) error {
+	query := `DELETE FROM email_confirm_tokens WHERE user_id = $1`
+	_, err := s.db.ExecContext(ctx, query, userID)
 	return err
 }
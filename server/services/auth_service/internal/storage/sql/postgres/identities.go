@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	identitiesSyncBatch = 1000
+
+	pqUniqueViolation = "23505"
+
+	// unique indexes on canonical forms, see migration 20240521120000_canonical_identities
+	usernameCanonicalKey = "users_username_canonical_key"
+	emailCanonicalKey    = "users_email_canonical_key"
+)
+
+// SyncCanonicalIdentities recomputes canonical username and email of users with non-ascii characters,
+// migration computes them with lower() that differs from case folding for few characters (e.g. 'ß').
+// It has to be run once after the migration. Users which new canonical form is already used by other user
+// are marked as colliding (the same way as in migration), their ids are returned.
+func (s *Storage) SyncCanonicalIdentities(ctx context.Context) ([]string, error) {
+	query := `
+SELECT id, username, username_canonical, email, email_canonical FROM users
+WHERE id > $1 AND (username ~ '[^\x01-\x7f]' OR email ~ '[^\x01-\x7f]')
+ORDER BY id
+LIMIT $2`
+
+	var collisions []string
+	afterID := ""
+	for {
+		var users []models.User
+		err := s.db.SelectContext(ctx, &users, query, afterID, identitiesSyncBatch)
+		if err != nil {
+			return collisions, err
+		}
+
+		for _, u := range users {
+			username, email := models.CanonicalUsername(u.Username), models.CanonicalEmail(u.Email)
+			if username == u.UsernameCanonical && email == u.EmailCanonical {
+				continue
+			}
+
+			collision, err := s.setCanonicalIdentity(ctx, u.ID, username, email)
+			if err != nil {
+				return collisions, err
+			}
+			if collision {
+				collisions = append(collisions, u.ID)
+			}
+		}
+
+		if len(users) < identitiesSyncBatch {
+			return collisions, nil
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
+
+// setCanonicalIdentity saves canonical username and email of user, if one of them is used by other user
+// the user is marked as colliding, so it is left out of unique index. Reports if user was marked.
+func (s *Storage) setCanonicalIdentity(ctx context.Context, id, username, email string) (bool, error) {
+	query := `
+UPDATE users SET username_canonical = $2, email_canonical = $3,
+	username_collision = username_collision OR $4, email_collision = email_collision OR $5
+WHERE id = $1`
+
+	var usernameCollision, emailCollision bool
+	for {
+		_, err := s.db.ExecContext(ctx, query, id, username, email, usernameCollision, emailCollision)
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr.Code != pqUniqueViolation {
+			return usernameCollision || emailCollision, err
+		}
+
+		// username and email can both collide, then the update is retried twice
+		switch {
+		case pqErr.Constraint == usernameCanonicalKey && !usernameCollision:
+			usernameCollision = true
+		case pqErr.Constraint == emailCanonicalKey && !emailCollision:
+			emailCollision = true
+		default:
+			return usernameCollision || emailCollision, err
+		}
+	}
+}
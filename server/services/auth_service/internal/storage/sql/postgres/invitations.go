@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateInvitation(ctx context.Context, inv *models.Invitation) error {
+	// create id
+	inv.ID = uuid.New().String()
+	inv.CreatedAt = time.Now()
+
+	query := `
+INSERT INTO invitations (id, code_hash, issuer_id, max_uses, expires_at, created_at)
+VALUES (:id, :code_hash, :issuer_id, :max_uses, :expires_at, :created_at)`
+	_, err := s.db.NamedExecContext(ctx, query, inv)
+	return err
+}
+
+func (s *Storage) GetInvitation(ctx context.Context, id string) (*models.Invitation, error) {
+	query := `SELECT * FROM invitations WHERE id = $1`
+	var inv models.Invitation
+	err := s.db.GetContext(ctx, &inv, query, id)
+	if err != nil {
+		// if here is no invitation it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (s *Storage) GetInvitationByHash(ctx context.Context, hash string) (*models.Invitation, error) {
+	query := `SELECT * FROM invitations WHERE code_hash = $1`
+	var inv models.Invitation
+	err := s.db.GetContext(ctx, &inv, query, hash)
+	if err != nil {
+		// if here is no invitation it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (s *Storage) ListInvitations(ctx context.Context, issuerID string) ([]models.Invitation, error) {
+	query := `SELECT * FROM invitations WHERE issuer_id = $1 ORDER BY created_at DESC`
+	var invs []models.Invitation
+	err := s.db.SelectContext(ctx, &invs, query, issuerID)
+	return invs, err
+}
+
+func (s *Storage) RevokeInvitation(ctx context.Context, id string) error {
+	query := `UPDATE invitations SET revoked = TRUE WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// CreateInvitedUser uses invitation with code hash and creates user in one transaction,
+// returns false (and doesn't create user) if invitation can't be used anymore
+// (e.g. its last use was taken by concurrent signup)
+func (s *Storage) CreateInvitedUser(ctx context.Context, user *models.User, codeHash string) (bool, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+UPDATE invitations SET uses = uses + 1
+WHERE code_hash = $1 AND revoked = FALSE AND uses < max_uses AND expires_at > $2
+RETURNING id`
+	var id string
+	err = tx.GetContext(ctx, &id, query, codeHash, time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	user.InvitationID = &id
+	err = createUser(ctx, tx, user)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (s *Storage) GetLoginThrottle(ctx context.Context, key string) (*models.LoginThrottle, error) {
+	query := `SELECT * FROM login_throttle WHERE key = $1`
+	var t models.LoginThrottle
+	err := s.db.GetContext(ctx, &t, query, key)
+	if err != nil {
+		// if here is no failures it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RegisterLoginFailure increments failures counter of the key and returns updated row,
+// counter starts from zero if previous failure was before windowStart
+func (s *Storage) RegisterLoginFailure(ctx context.Context, key string, windowStart time.Time) (*models.LoginThrottle, error) {
+	query := `
+INSERT INTO login_throttle (key, failures, last_failure_at)
+VALUES ($1, 1, $2)
+ON CONFLICT (key) DO UPDATE SET
+    failures = CASE WHEN login_throttle.last_failure_at < $3 THEN 1 ELSE login_throttle.failures + 1 END,
+    last_failure_at = EXCLUDED.last_failure_at
+RETURNING *`
+	var t models.LoginThrottle
+	err := s.db.GetContext(ctx, &t, query, key, time.Now(), windowStart)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *Storage) BlockLogin(ctx context.Context, key string, until time.Time) error {
+	query := `UPDATE login_throttle SET blocked_until = $2 WHERE key = $1`
+	_, err := s.db.ExecContext(ctx, query, key, until)
+	return err
+}
+
+func (s *Storage) ResetLoginFailures(ctx context.Context, key string) error {
+	query := `DELETE FROM login_throttle WHERE key = $1`
+	_, err := s.db.ExecContext(ctx, query, key)
+	return err
+}
+
+// DeleteExpiredLoginThrottles deletes rows with last failure before windowStart
+// that aren't blocked at now, such rows don't change anything anymore
+func (s *Storage) DeleteExpiredLoginThrottles(ctx context.Context, windowStart, now time.Time) (int64, error) {
+	query := `
+DELETE FROM login_throttle
+WHERE last_failure_at < $1 AND (blocked_until IS NULL OR blocked_until < $2)`
+	res, err := s.db.ExecContext(ctx, query, windowStart, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
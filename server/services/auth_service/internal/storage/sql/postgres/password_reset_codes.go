@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// CreatePasswordResetCode saves new code, previous codes of the user are removed
+// so only the latest sent code can be used
+func (s *Storage) CreatePasswordResetCode(ctx context.Context, userID, codeHash string, expiresAt time.Time) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM password_reset_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+INSERT INTO password_reset_codes (user_id, code_hash, expires_at)
+VALUES ($1, $2, $3)`
+	_, err = tx.ExecContext(ctx, query, userID, codeHash, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetPasswordResetCode returns the latest code of the user that wasn't used yet
+func (s *Storage) GetPasswordResetCode(ctx context.Context, userID string) (*models.PasswordResetCode, error) {
+	query := `
+SELECT * FROM password_reset_codes
+WHERE user_id = $1 AND used = FALSE
+ORDER BY created_at DESC
+LIMIT 1`
+	var code models.PasswordResetCode
+	err := s.db.GetContext(ctx, &code, query, userID)
+	if err != nil {
+		// if here is no code it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (s *Storage) IncrementPasswordResetAttempts(ctx context.Context, id int) error {
+	query := `UPDATE password_reset_codes SET attempts = attempts + 1 WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkPasswordResetCodeUsed marks code as used and returns false
+// if it was already used (e.g. by concurrent request)
+func (s *Storage) MarkPasswordResetCodeUsed(ctx context.Context, id int) (bool, error) {
+	query := `UPDATE password_reset_codes SET used = TRUE WHERE id = $1 AND used = FALSE`
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+
+	"github.com/lib/pq"
+)
+
+// GetProfiles returns profiles of existing users with given ids,
+// users that never updated profile get empty one
+func (s *Storage) GetProfiles(ctx context.Context, userIDs []string) ([]models.Profile, error) {
+	query := `
+SELECT u.id AS user_id,
+	u.username,
+	COALESCE(p.display_name, '') AS display_name,
+	COALESCE(p.bio, '') AS bio,
+	COALESCE(p.avatar_ref, '') AS avatar_ref,
+	COALESCE(p.time_zone, '') AS time_zone,
+	COALESCE(p.status_text, '') AS status_text,
+	p.status_expires_at
+FROM users u
+LEFT JOIN user_profiles p ON p.user_id = u.id
+WHERE u.id = ANY($1)`
+	var profiles []models.Profile
+	err := s.db.SelectContext(ctx, &profiles, query, pq.Array(userIDs))
+	return profiles, err
+}
+
+func (s *Storage) UpsertProfile(ctx context.Context, profile *models.Profile) error {
+	query := `
+INSERT INTO user_profiles (user_id, display_name, bio, avatar_ref, time_zone, status_text, status_expires_at)
+VALUES (:user_id, :display_name, :bio, :avatar_ref, :time_zone, :status_text, :status_expires_at)
+ON CONFLICT (user_id) DO UPDATE SET
+	display_name = EXCLUDED.display_name,
+	bio = EXCLUDED.bio,
+	avatar_ref = EXCLUDED.avatar_ref,
+	time_zone = EXCLUDED.time_zone,
+	status_text = EXCLUDED.status_text,
+	status_expires_at = EXCLUDED.status_expires_at,
+	updated_at = CURRENT_TIMESTAMP`
+	_, err := s.db.NamedExecContext(ctx, query, profile)
+	return err
+}
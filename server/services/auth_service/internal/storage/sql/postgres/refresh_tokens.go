@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	// create id
+	token.ID = uuid.New().String()
+
+	query := `
+INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at)
+VALUES (:id, :user_id, :family_id, :token_hash, :expires_at)`
+	_, err := s.db.NamedExecContext(ctx, query, token)
+	return err
+}
+
+func (s *Storage) GetRefreshTokenByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	query := `SELECT * FROM refresh_tokens WHERE token_hash = $1`
+	var token models.RefreshToken
+	err := s.db.GetContext(ctx, &token, query, hash)
+	if err != nil {
+		// if here is no token it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed marks token as used and returns false
+// if it was already used (e.g. by concurrent request)
+func (s *Storage) MarkRefreshTokenUsed(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE refresh_tokens SET used = TRUE WHERE id = $1 AND used = FALSE`
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (s *Storage) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	query := `UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1 AND revoked = FALSE`
+	_, err := s.db.ExecContext(ctx, query, userID)
+	return err
+}
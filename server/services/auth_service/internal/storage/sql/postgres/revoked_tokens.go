@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+// RevokeToken saves jti of the token as revoked,
+// rows of tokens that are already expired are removed here as they can't be used anyway
+func (s *Storage) RevokeToken(ctx context.Context, jti, userID string, expiresAt time.Time) error {
+	query := `
+INSERT INTO revoked_tokens (jti, user_id, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (jti) DO NOTHING`
+	_, err := s.db.ExecContext(ctx, query, jti, userID, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	query = `DELETE FROM revoked_tokens WHERE expires_at < $1`
+	_, err = s.db.ExecContext(ctx, query, time.Now())
+	return err
+}
+
+// RevokeUserTokens revokes all tokens of the user issued before or in the same second as given time
+func (s *Storage) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	query := `
+INSERT INTO user_token_revocations (user_id, revoked_before)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET revoked_before = GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before)`
+	_, err := s.db.ExecContext(ctx, query, userID, before)
+	return err
+}
+
+// IsTokenRevoked checks if token was revoked by its jti or together with all tokens of the user,
+// issuedAt has seconds precision so token issued in the second of revocation counts as revoked
+func (s *Storage) IsTokenRevoked(ctx context.Context, jti, userID string, issuedAt time.Time) (bool, error) {
+	query := `
+SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)
+	OR EXISTS(SELECT 1 FROM user_token_revocations WHERE user_id = $2 AND revoked_before >= $3)`
+	var revoked bool
+	err := s.db.GetContext(ctx, &revoked, query, jti, userID, issuedAt)
+	return revoked, err
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateSession(ctx context.Context, session *models.Session) error {
+	// create id
+	session.ID = uuid.New().String()
+
+	query := `
+INSERT INTO sessions (id, user_id, device_name, user_agent, ip)
+VALUES (:id, :user_id, :device_name, :user_agent, :ip)`
+	_, err := s.db.NamedExecContext(ctx, query, session)
+	return err
+}
+
+func (s *Storage) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	query := `SELECT * FROM sessions WHERE id = $1`
+	var session models.Session
+	err := s.db.GetContext(ctx, &session, query, id)
+	if err != nil {
+		// if here is no session it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListUserSessions returns not revoked sessions of the user that were seen after activeSince
+func (s *Storage) ListUserSessions(ctx context.Context, userID string, activeSince time.Time) ([]models.Session, error) {
+	query := `
+SELECT * FROM sessions
+WHERE user_id = $1 AND revoked = FALSE AND last_seen_at > $2
+ORDER BY last_seen_at DESC`
+	var sessions []models.Session
+	err := s.db.SelectContext(ctx, &sessions, query, userID, activeSince)
+	return sessions, err
+}
+
+func (s *Storage) TouchSession(ctx context.Context, id, ip string) error {
+	query := `UPDATE sessions SET last_seen_at = $2, ip = $3 WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id, time.Now(), ip)
+	return err
+}
+
+// RevokeSession revokes session and all its refresh tokens
+func (s *Storage) RevokeSession(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `UPDATE sessions SET revoked = TRUE WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked = TRUE WHERE family_id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) RevokeUserSessions(ctx context.Context, userID string) error {
+	query := `UPDATE sessions SET revoked = TRUE WHERE user_id = $1`
+	_, err := s.db.ExecContext(ctx, query, userID)
+	return err
+}
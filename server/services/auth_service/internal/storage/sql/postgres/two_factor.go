@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// SaveTOTPSecret saves new not yet enabled secret of the user, previous one is replaced
+func (s *Storage) SaveTOTPSecret(ctx context.Context, userID, secret string) error {
+	query := `
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET
+    secret = EXCLUDED.secret,
+    enabled = FALSE,
+    last_used_step = 0,
+    created_at = CURRENT_TIMESTAMP`
+	_, err := s.db.ExecContext(ctx, query, userID, secret)
+	return err
+}
+
+func (s *Storage) GetTOTP(ctx context.Context, userID string) (*models.TOTP, error) {
+	query := `SELECT * FROM user_totp WHERE user_id = $1`
+	var t models.TOTP
+	err := s.db.GetContext(ctx, &t, query, userID)
+	if err != nil {
+		// if here is no totp it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// EnableTOTP enables totp of the user and replaces recovery codes with new ones
+func (s *Storage) EnableTOTP(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `UPDATE user_totp SET enabled = TRUE, last_used_step = $2 WHERE user_id = $1`, userID, step)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		_, err = tx.ExecContext(ctx, `INSERT INTO totp_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) DisableTOTP(ctx context.Context, userID string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep saves step of accepted code and returns false
+// if code from this or later step was already used
+func (s *Storage) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `UPDATE user_totp SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+	res, err := s.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// UseRecoveryCode marks recovery code as used and returns false if there is no such unused code
+func (s *Storage) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query := `UPDATE totp_recovery_codes SET used = TRUE WHERE user_id = $1 AND code_hash = $2 AND used = FALSE`
+	res, err := s.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *Storage) CreateLoginChallenge(ctx context.Context, userID, challengeHash string, expiresAt time.Time) error {
+	query := `
+INSERT INTO login_challenges (user_id, challenge_hash, expires_at)
+VALUES ($1, $2, $3)`
+	_, err := s.db.ExecContext(ctx, query, userID, challengeHash, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	// remove expired challenges
+	_, err = s.db.ExecContext(ctx, `DELETE FROM login_challenges WHERE expires_at < $1`, time.Now())
+	return err
+}
+
+func (s *Storage) GetLoginChallengeByHash(ctx context.Context, hash string) (*models.LoginChallenge, error) {
+	query := `SELECT * FROM login_challenges WHERE challenge_hash = $1`
+	var c models.LoginChallenge
+	err := s.db.GetContext(ctx, &c, query, hash)
+	if err != nil {
+		// if here is no challenge it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *Storage) IncrementLoginChallengeAttempts(ctx context.Context, id int) error {
+	query := `UPDATE login_challenges SET attempts = attempts + 1 WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkLoginChallengeUsed marks challenge as used and returns false
+// if it was already used (e.g. by concurrent request)
+func (s *Storage) MarkLoginChallengeUsed(ctx context.Context, id int) (bool, error) {
+	query := `UPDATE login_challenges SET used = TRUE WHERE id = $1 AND used = FALSE`
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+
+	"github.com/lib/pq"
+)
+
+// CreateUserBlock blocks user, blocking already blocked user does nothing
+func (s *Storage) CreateUserBlock(ctx context.Context, blockerID, blockedID string) error {
+	query := `
+INSERT INTO user_blocks (blocker_id, blocked_id)
+VALUES ($1, $2)
+ON CONFLICT (blocker_id, blocked_id) DO NOTHING`
+	_, err := s.db.ExecContext(ctx, query, blockerID, blockedID)
+	return err
+}
+
+// DeleteUserBlock unblocks user, returns false if user wasn't blocked
+func (s *Storage) DeleteUserBlock(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+	res, err := s.db.ExecContext(ctx, query, blockerID, blockedID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *Storage) ListUserBlocks(ctx context.Context, blockerID string) ([]models.UserBlock, error) {
+	query := `
+SELECT b.blocker_id, b.blocked_id, u.username, b.created_at
+FROM user_blocks b
+JOIN users u ON u.id = b.blocked_id
+WHERE b.blocker_id = $1
+ORDER BY b.created_at DESC`
+	var blocks []models.UserBlock
+	err := s.db.SelectContext(ctx, &blocks, query, blockerID)
+	return blocks, err
+}
+
+// GetBlockRelations returns which of otherIDs blocked the user (blockedBy)
+// and which of them are blocked by the user (blocked)
+func (s *Storage) GetBlockRelations(ctx context.Context, userID string, otherIDs []string) (blockedBy, blocked []string, err error) {
+	query := `
+SELECT blocker_id, blocked_id FROM user_blocks
+WHERE (blocked_id = $1 AND blocker_id = ANY($2))
+   OR (blocker_id = $1 AND blocked_id = ANY($2))`
+	var blocks []models.UserBlock
+	err = s.db.SelectContext(ctx, &blocks, query, userID, pq.Array(otherIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, b := range blocks {
+		if b.BlockedID == userID {
+			blockedBy = append(blockedBy, b.BlockerID)
+		} else {
+			blocked = append(blocked, b.BlockedID)
+		}
+	}
+	return blockedBy, blocked, nil
+}
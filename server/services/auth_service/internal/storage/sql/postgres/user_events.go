@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"time"
+)
+
+// SetUserDeleted marks user and bots owned by user as pending deletion,
+// nil deletedAt restores them (only bots deleted together with user are restored)
+func (s *Storage) SetUserDeleted(ctx context.Context, id string, deletedAt *time.Time) error {
+	var query string
+	if deletedAt != nil {
+		query = `
+UPDATE users SET deleted_at = $2
+WHERE (id = $1 OR owner_id = $1) AND deleted_at IS NULL`
+		_, err := s.db.ExecContext(ctx, query, id, *deletedAt)
+		return err
+	}
+
+	query = `
+UPDATE users SET deleted_at = NULL
+WHERE id = $1 OR (owner_id = $1 AND deleted_at = (SELECT deleted_at FROM users WHERE id = $1))`
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// PurgeDeletedUsers deletes users that are pending deletion since before
+// and creates user_deleted event for each of them, returns ids of deleted users
+func (s *Storage) PurgeDeletedUsers(ctx context.Context, before time.Time) ([]string, error) {
+	// event is created in the same statement, so it can't be lost
+	query := `
+WITH deleted AS (
+	DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1 RETURNING id
+)
+INSERT INTO user_events (type, user_id)
+SELECT $2, id FROM deleted
+RETURNING user_id`
+	var ids []string
+	err := s.db.SelectContext(ctx, &ids, query, before, models.UserEventDeleted)
+	return ids, err
+}
+
+// ListUserEvents returns events with id greater than afterID in order of id
+func (s *Storage) ListUserEvents(ctx context.Context, afterID int64, limit int) ([]models.UserEvent, error) {
+	query := `SELECT * FROM user_events WHERE id > $1 ORDER BY id LIMIT $2`
+	var events []models.UserEvent
+	err := s.db.SelectContext(ctx, &events, query, afterID, limit)
+	return events, err
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (s *Storage) CreateOIDCState(ctx context.Context, state *models.OIDCState) error {
+	query := `
+INSERT INTO oidc_login_states (state, provider, nonce, code_verifier, user_id, expires_at)
+VALUES (:state, :provider, :nonce, :code_verifier, :user_id, :expires_at)`
+	_, err := s.db.NamedExecContext(ctx, query, state)
+	if err != nil {
+		return err
+	}
+
+	// remove expired states
+	_, err = s.db.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < $1`, time.Now())
+	return err
+}
+
+// TakeOIDCState deletes state and returns it, so every state can be used only once
+func (s *Storage) TakeOIDCState(ctx context.Context, state string) (*models.OIDCState, error) {
+	query := `DELETE FROM oidc_login_states WHERE state = $1 RETURNING *`
+	var st models.OIDCState
+	err := s.db.GetContext(ctx, &st, query, state)
+	if err != nil {
+		// if here is no state it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (s *Storage) CreateUserIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	query := `
+INSERT INTO user_identities (user_id, provider, subject, email)
+VALUES (:user_id, :provider, :subject, :email)`
+	_, err := s.db.NamedExecContext(ctx, query, identity)
+	return err
+}
+
+func (s *Storage) GetUserIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	query := `SELECT * FROM user_identities WHERE provider = $1 AND subject = $2`
+	var identity models.UserIdentity
+	err := s.db.GetContext(ctx, &identity, query, provider, subject)
+	if err != nil {
+		// if here is no identity it isn't error
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (s *Storage) ListUserIdentities(ctx context.Context, userID string) ([]models.UserIdentity, error) {
+	query := `SELECT * FROM user_identities WHERE user_id = $1 ORDER BY created_at`
+	var identities []models.UserIdentity
+	err := s.db.SelectContext(ctx, &identities, query, userID)
+	return identities, err
+}
@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"strings"
+)
+
+// SearchUsers finds users whose username or display name starts with query or is similar to it
+// (pg_trgm), deleted users are never returned. Prefix matches are ranked above fuzzy ones.
+func (s *Storage) SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserSearchResult, error) {
+	q := strings.ToLower(search.Query)
+	// escape LIKE wildcards so query is matched literally
+	prefix := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(q) + "%"
+
+	query := `
+SELECT u.*,
+	COALESCE(p.display_name, '') AS display_name,
+	COALESCE(p.avatar_ref, '') AS avatar_ref,
+	CASE
+		WHEN lower(u.username) = $1 THEN 3
+		WHEN lower(u.username) LIKE $2 THEN 2
+		WHEN lower(p.display_name) LIKE $2 THEN 1.5
+		ELSE 0
+	END + GREATEST(similarity(lower(u.username), $1), similarity(lower(COALESCE(p.display_name, '')), $1)) AS rank
+FROM users u
+LEFT JOIN user_profiles p ON p.user_id = u.id
+WHERE u.deleted_at IS NULL
+	AND ($3 OR u.suspended_at IS NULL)
+	AND (lower(u.username) LIKE $2 OR lower(p.display_name) LIKE $2
+		OR lower(u.username) % $1 OR lower(p.display_name) % $1)
+ORDER BY rank DESC, u.username, u.id
+LIMIT $4 OFFSET $5`
+
+	var results []models.UserSearchResult
+	err := s.db.SelectContext(ctx, &results, query, q, prefix, search.IncludeSuspended, search.Limit, search.Offset)
+	return results, err
+}
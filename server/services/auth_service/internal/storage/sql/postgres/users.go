@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Storage struct {
@@ -21,26 +22,42 @@ func New(db *sqlx.DB) *Storage {
 }
 
 func (s *Storage) CreateUser(ctx context.Context, user *models.User) error {
+	return createUser(ctx, s.db, user)
+}
+
+// createUser inserts user with db or transaction
+func createUser(ctx context.Context, db sqlx.ExtContext, user *models.User) error {
 	// create id
 	user.ID = uuid.New().String()
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	user.UsernameCanonical = models.CanonicalUsername(user.Username)
+	user.EmailCanonical = models.CanonicalEmail(user.Email)
 
 	query :=
 		`
-INSERT INTO users (id, username, email, encrypted_password, confirmed_email, created_at)
-VALUES (:id, :username, :email, :encrypted_password, :confirmed_email, :created_at)
+INSERT INTO users (id, username, username_canonical, email, email_canonical, encrypted_password, confirmed_email, created_at, is_bot, owner_id, role, invitation_id)
+VALUES (:id, :username, :username_canonical, :email, :email_canonical, :encrypted_password, :confirmed_email, :created_at, :is_bot, :owner_id, :role, :invitation_id)
 `
-	_, err := s.db.NamedExecContext(ctx, query, user)
+	_, err := sqlx.NamedExecContext(ctx, db, query, user)
 	return err
 }
 
 func (s *Storage) UpdateUser(ctx context.Context, user *models.User) error {
+	user.UsernameCanonical = models.CanonicalUsername(user.Username)
+	user.EmailCanonical = models.CanonicalEmail(user.Email)
+
 	query :=
 		`
 UPDATE users SET username = :username,
+ 	username_canonical = :username_canonical,
+ 	username_collision = username_collision AND username_canonical = :username_canonical,
  	email = :email,
+ 	email_canonical = :email_canonical,
+ 	email_collision = email_collision AND email_canonical = :email_canonical,
  	encrypted_password = :encrypted_password,
- 	confirmed_email = :confirmed_email,
- 	created_at = :created_at 
+ 	confirmed_email = :confirmed_email
 	WHERE id = :id
 	`
 
@@ -48,9 +65,9 @@ UPDATE users SET username = :username,
 	return err
 }
 
-func (s *Storage) DeleteUser(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := s.db.ExecContext(ctx, query, id)
+func (s *Storage) SetUserPassword(ctx context.Context, id string, encryptedPassword []byte) error {
+	query := `UPDATE users SET encrypted_password = $1 WHERE id = $2`
+	_, err := s.db.ExecContext(ctx, query, encryptedPassword, id)
 	return err
 }
 
@@ -68,10 +85,21 @@ func (s *Storage) GetUserByID(ctx context.Context, id string) (*models.User, err
 	return &user, nil
 }
 
+// GetUsersByIDs returns existing users with given ids, unknown ids are skipped
+func (s *Storage) GetUsersByIDs(ctx context.Context, ids []string) ([]models.User, error) {
+	query := `SELECT * FROM users WHERE id = ANY($1)`
+	var users []models.User
+	err := s.db.SelectContext(ctx, &users, query, pq.Array(ids))
+	return users, err
+}
+
+// FindUserByEmail finds user by canonical form of email (see models.CanonicalEmail).
+// Accounts with colliding email (see 20240521120000_canonical_identities) are used only
+// if there is no other, exact match and older account go first
 func (s *Storage) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `SELECT * FROM users WHERE email = $1`
+	query := `SELECT * FROM users WHERE email_canonical = $1 ORDER BY email_collision, email = $2 DESC, created_at, id LIMIT 1`
 	var user models.User
-	err := s.db.GetContext(ctx, &user, query, email)
+	err := s.db.GetContext(ctx, &user, query, models.CanonicalEmail(email), email)
 	if err != nil {
 		// if here is no user it isn't error
 		if errors.Is(err, sql.ErrNoRows) {
@@ -82,10 +110,12 @@ func (s *Storage) FindUserByEmail(ctx context.Context, email string) (*models.Us
 	return &user, nil
 }
 
+// FindUserByUsername finds user by canonical form of username (see models.CanonicalUsername),
+// colliding accounts are ordered the same way as in FindUserByEmail
 func (s *Storage) FindUserByUsername(ctx context.Context, username string) (*models.User, error) {
-	query := `SELECT * FROM users WHERE username = $1`
+	query := `SELECT * FROM users WHERE username_canonical = $1 ORDER BY username_collision, username = $2 DESC, created_at, id LIMIT 1`
 	var user models.User
-	err := s.db.GetContext(ctx, &user, query, username)
+	err := s.db.GetContext(ctx, &user, query, models.CanonicalUsername(username), username)
 	if err != nil {
 		// if here is no user it isn't error
 		if errors.Is(err, sql.ErrNoRows) {
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"auth_service/internal/models"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// testStorage connects to database from TEST_POSTGRES_URL, migrations have to be applied
+// (see postgres_up in server/Makefile). Test is skipped if variable isn't set.
+func testStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	url := os.Getenv("TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("TEST_POSTGRES_URL isn't set")
+	}
+
+	db, err := sqlx.Connect("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return New(db)
+}
+
+func TestFindUserCollidingAccounts(t *testing.T) {
+	s := testStorage(t)
+	ctx := context.Background()
+
+	suffix := uuid.NewString()[:8]
+	username := "Collision_" + suffix
+	email := "collision_" + suffix + "@example.com"
+
+	// account that owns username and email
+	owner := &models.User{
+		Username:          username,
+		Email:             email,
+		EncryptedPassword: []byte{},
+		CreatedAt:         time.Now(),
+	}
+	if err := s.CreateUser(ctx, owner); err != nil {
+		t.Fatal(err)
+	}
+
+	// older account with the same canonical username and email, it is left out of
+	// unique indexes, so it is created with other values and then changed
+	other := &models.User{
+		Username:          "other_" + suffix,
+		Email:             "other_" + suffix + "@example.com",
+		EncryptedPassword: []byte{},
+		CreatedAt:         time.Now().Add(-time.Hour),
+	}
+	if err := s.CreateUser(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		s.db.ExecContext(context.Background(), `DELETE FROM users WHERE id = ANY($1)`, pq.Array([]string{owner.ID, other.ID}))
+	})
+
+	_, err := s.db.ExecContext(ctx, `
+UPDATE users SET username = $2, username_canonical = $3, username_collision = TRUE,
+	email = $4, email_canonical = $5, email_collision = TRUE
+	WHERE id = $1`,
+		other.ID, "COLLISION_"+suffix, owner.UsernameCanonical, "collision_"+suffix+"@EXAMPLE.com", owner.EmailCanonical)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// colliding account is older and matches exactly, owner still has to be found every time
+	for i := 0; i < 5; i++ {
+		u, err := s.FindUserByEmail(ctx, "collision_"+suffix+"@EXAMPLE.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u == nil || u.ID != owner.ID {
+			t.Fatalf("FindUserByEmail returned %+v, want owner %s", u, owner.ID)
+		}
+
+		u, err = s.FindUserByUsername(ctx, "COLLISION_"+suffix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u == nil || u.ID != owner.ID {
+			t.Fatalf("FindUserByUsername returned %+v, want owner %s", u, owner.ID)
+		}
+	}
+}
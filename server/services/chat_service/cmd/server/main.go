@@ -22,6 +22,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"shared/token_verifier"
 	"syscall"
 )
 
@@ -43,6 +44,16 @@ func main() {
 	storage := redisSt.New(cl)
 	log := setupLogger(cfg.Env)
 
+	// Index messages sent before messages were indexed by author, it is done only once
+	n, err := storage.BackfillUserMessages(context.Background())
+	if err != nil {
+		log.Error("failed to index messages by author", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if n > 0 {
+		log.Info("Indexed old messages by author", slog.Int("count", n))
+	}
+
 	authService, err := auth.Connect(log, cfg.OtherServices.AuthService, &cfg.OtherServices.AuthCerts)
 	if err != nil {
 		log.Error("failed to connect to auth service", slog.String("error", err.Error()))
@@ -55,7 +66,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	s := service.New(log, authService, roomsService, storage)
+	// verify tokens locally if it is enabled
+	var verifier service.TokenVerifier
+	if cfg.Tokens.LocalVerification {
+		verifier = token_verifier.New(log, authService, authService, cfg.Tokens.CheckInterval)
+	}
+
+	s := service.New(log, authService, roomsService, verifier, storage)
 
 	loggingOpts := []logging.Option{
 		logging.WithLogOnEvents(
@@ -82,6 +99,14 @@ func main() {
 
 	service.Register(gRPCServer, s)
 
+	// Send stored messages to active users
+	go s.SendMessagesLoop()
+
+	// Anonymize messages of deleted users
+	eventsCtx, stopEvents := context.WithCancel(context.Background())
+	defer stopEvents()
+	go s.ConsumeUserEventsLoop(eventsCtx, cfg.UserEvents.PollInterval)
+
 	// Start gRPC server
 	go func() {
 		l, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
@@ -78,5 +78,70 @@ func (c *Client) AuthenticateUser(ctx context.Context, token []byte) (*models.Us
 		Username:       resp.User.Username,
 		Email:          resp.User.Email,
 		ConfirmedEmail: resp.User.Verified,
+		Scopes:         resp.GetScopes(),
 	}, nil
 }
+
+// CheckToken checks locally verified token with auth service,
+// error is returned if token was revoked or its user can't use it anymore
+func (c *Client) CheckToken(ctx context.Context, token string) error {
+	_, err := c.AuthenticateUser(ctx, []byte(token))
+	return err
+}
+
+// GetProfiles returns profiles of users with given ids by user id,
+// users that don't exist are missing in map
+func (c *Client) GetProfiles(ctx context.Context, ids []string) (map[string]*auth.Profile, error) {
+	resp, err := c.client.GetProfiles(ctx, &auth.GetProfilesRequest{
+		UserIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]*auth.Profile, len(resp.GetProfiles()))
+	for _, p := range resp.GetProfiles() {
+		profiles[p.GetUserId()] = p
+	}
+
+	return profiles, nil
+}
+
+// ListUserEvents returns events about users (e.g. deleted users) with id greater than afterID
+func (c *Client) ListUserEvents(ctx context.Context, afterID int64, limit int) ([]*auth.UserEvent, error) {
+	resp, err := c.client.ListUserEvents(ctx, &auth.ListUserEventsRequest{
+		AfterId: afterID,
+		Limit:   int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetEvents(), nil
+}
+
+// ListBlocked returns ids of users blocked by owner of the token
+func (c *Client) ListBlocked(ctx context.Context, token []byte) ([]string, error) {
+	resp, err := c.client.ListBlocked(ctx, &auth.ListBlockedRequest{
+		Token: &auth.Token{Token: token},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(resp.GetUsers()))
+	for _, u := range resp.GetUsers() {
+		ids = append(ids, u.GetUserId())
+	}
+
+	return ids, nil
+}
+
+func (c *Client) GetSigningKeys(ctx context.Context) ([]*auth.SigningKey, error) {
+	resp, err := c.client.GetSigningKeys(ctx, &auth.GetSigningKeysRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetKeys(), nil
+}
@@ -8,13 +8,18 @@ type Msg struct {
 	UserID string
 	Text   string
 
+	// SenderDisplayName is name from sender profile, it isn't stored with message
+	SenderDisplayName string
+
 	// TODO: Payload
 }
 
 func (m *Msg) ToProto() *chat.Message {
 	return &chat.Message{
-		ChatID:  m.ChatID,
-		Text:    m.Text,
-		Payload: nil, // TODO: Add payload
+		ChatID:            m.ChatID,
+		Text:              m.Text,
+		Payload:           nil, // TODO: Add payload
+		UserId:            m.UserID,
+		SenderDisplayName: m.SenderDisplayName,
 	}
 }
@@ -1,8 +1,37 @@
 package models
 
+// Scopes of api keys (see auth service)
+const (
+	ScopeChatRead  = "chat:read"
+	ScopeChatWrite = "chat:write"
+)
+
+// DeletedUserID is set as author of messages of deleted users
+const DeletedUserID = "deleted"
+
 type User struct {
 	ID             string
 	Username       string
 	Email          string
 	ConfirmedEmail bool
+
+	// DisplayName is from user profile, falls back to username
+	DisplayName string
+
+	// Scopes are set only when user is authenticated with api key,
+	// nil means that user isn't limited
+	Scopes []string
+}
+
+// HasScope reports if user can do actions allowed by scope
+func (u *User) HasScope(scope string) bool {
+	if u.Scopes == nil {
+		return true
+	}
+	for _, s := range u.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
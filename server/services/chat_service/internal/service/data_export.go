@@ -0,0 +1,70 @@
+package service
+
+import (
+	"chat_service/internal/models"
+	"encoding/json"
+	"github.com/zumosik/grpc_chat_protos/go/chat"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+// exportChunkSize is max size of data in one ExportUserData response
+const exportChunkSize = 64 * 1024
+
+type exportedMsg struct {
+	ID     string `json:"id"`
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// ExportUserData sends JSON with messages sent by the user for personal data export (see auth service),
+// whole history can be bigger than max grpc message, so it is sent in chunks
+func (s *Service) ExportUserData(_ *chat.ExportUserDataRequest, stream chat.ChatService_ExportUserDataServer) error {
+	ctx := stream.Context()
+
+	// 1. authenticate user using token and auth service
+	user, _, err := s.getUser(ctx)
+	if err != nil {
+		return err
+	}
+	if !user.HasScope(models.ScopeChatRead) {
+		return status.Error(codes.PermissionDenied, "missing scope "+models.ScopeChatRead)
+	}
+
+	// 2. get messages of the user
+	msgs, err := s.storage.GetUserMessages(ctx, user.ID)
+	if err != nil {
+		s.l.Error("Failed to get user messages", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	exported := make([]exportedMsg, 0, len(msgs))
+	for _, msg := range msgs {
+		exported = append(exported, exportedMsg{
+			ID:     msg.ID,
+			ChatID: msg.ChatID,
+			Text:   msg.Text,
+		})
+	}
+
+	data, err := json.MarshalIndent(struct {
+		UserID   string        `json:"user_id"`
+		Messages []exportedMsg `json:"messages"`
+	}{user.ID, exported}, "", "  ")
+	if err != nil {
+		s.l.Error("Failed to marshal messages", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	// 3. send data in chunks
+	for len(data) > 0 {
+		n := min(len(data), exportChunkSize)
+		if err := stream.Send(&chat.ExportUserDataResponse{Chunk: data[:n]}); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+
+	return nil
+}
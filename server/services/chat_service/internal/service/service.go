@@ -4,6 +4,7 @@ import (
 	"chat_service/internal/models"
 	"context"
 	"errors"
+	"github.com/zumosik/grpc_chat_protos/go/auth"
 	"github.com/zumosik/grpc_chat_protos/go/chat"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -11,10 +12,23 @@ import (
 	"google.golang.org/grpc/status"
 	"io"
 	"log/slog"
+	"shared/token_verifier"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	TokenMetadataKey = "token"
+
+	// APIKeyPrefix is prefix of api keys issued by auth service, they can be checked only by auth service
+	APIKeyPrefix = "cgk_"
+
+	// blocksRefreshInterval is how often block lists of connected users are loaded again,
+	// so blocking someone is applied to open streams with this delay
+	blocksRefreshInterval = 30 * time.Second
+	// blocksTimeout limits waiting for auth service when block list is loaded
+	blocksTimeout = 3 * time.Second
 )
 
 type RoomsService interface {
@@ -23,19 +37,32 @@ type RoomsService interface {
 
 type AuthService interface {
 	AuthenticateUser(ctx context.Context, token []byte) (*models.User, error)
+	GetProfiles(ctx context.Context, ids []string) (map[string]*auth.Profile, error)
+	ListUserEvents(ctx context.Context, afterID int64, limit int) ([]*auth.UserEvent, error)
+	ListBlocked(ctx context.Context, token []byte) ([]string, error)
+}
+
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*token_verifier.Claims, error)
 }
 
 type MessageStorage interface {
 	CreateMessage(ctx context.Context, msg *models.Msg) (*models.Msg, error)
 	GetMsgByID(ctx context.Context, id string) (*models.Msg, error)
 	DeleteMsgByID(ctx context.Context, id string) error
+	GetUserMessages(ctx context.Context, userID string) ([]*models.Msg, error)
+
+	AnonymizeUserMessages(ctx context.Context, userID string) error
+	GetEventCursor(ctx context.Context) (int64, error)
+	SetEventCursor(ctx context.Context, id int64) error
 }
 
 type Service struct {
 	l *slog.Logger
 
-	authService  AuthService
-	roomsService RoomsService
+	authService   AuthService
+	roomsService  RoomsService
+	tokenVerifier TokenVerifier
 
 	storage MessageStorage
 
@@ -44,18 +71,26 @@ type Service struct {
 
 	messagesToSend chan *models.Msg
 
+	// blocked holds users blocked by each connected user, it is loaded when stream is opened
+	// and refreshed while it is open, so messages are sent without calling auth service
+	blockedMu sync.RWMutex
+	blocked   map[string]map[string]bool
+
 	chat.UnimplementedChatServiceServer
 }
 
-func New(logger *slog.Logger, authService AuthService, roomsService RoomsService, storage MessageStorage) *Service {
+// New creates chat service, tokenVerifier can be nil, then every token is checked by auth service
+func New(logger *slog.Logger, authService AuthService, roomsService RoomsService, tokenVerifier TokenVerifier, storage MessageStorage) *Service {
 	return &Service{
 		l:              logger,
 		authService:    authService,
 		roomsService:   roomsService,
+		tokenVerifier:  tokenVerifier,
 		storage:        storage,
 		userServers:    make(map[string]chat.ChatService_StreamServer),
 		activeUsers:    make(map[string][]string),
 		messagesToSend: make(chan *models.Msg, 100),
+		blocked:        make(map[string]map[string]bool),
 	}
 }
 
@@ -66,7 +101,14 @@ func Register(server *grpc.Server, service *Service) {
 func (s *Service) SendMessagesLoop() {
 	for msg := range s.messagesToSend {
 		for _, userID := range s.activeUsers[msg.ChatID] {
-			server := s.userServers[userID]
+			// users that blocked the sender don't see their messages
+			if s.isBlocked(userID, msg.UserID) {
+				continue
+			}
+			server, ok := s.userServers[userID]
+			if !ok {
+				continue
+			}
 			err := server.Send(&chat.StreamResponse{
 				Event: &chat.StreamResponse_ClientMessage{
 					ClientMessage: msg.ToProto(),
@@ -79,14 +121,91 @@ func (s *Service) SendMessagesLoop() {
 	}
 }
 
+// isBlocked reports if connected user blocked the other user
+func (s *Service) isBlocked(userID, otherID string) bool {
+	s.blockedMu.RLock()
+	defer s.blockedMu.RUnlock()
+
+	return s.blocked[userID][otherID]
+}
+
+// loadBlocked loads users blocked by owner of the token and saves them for user
+func (s *Service) loadBlocked(ctx context.Context, userID, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, blocksTimeout)
+	defer cancel()
+
+	ids, err := s.authService.ListBlocked(ctx, []byte(token))
+	if err != nil {
+		return err
+	}
+
+	blocked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		blocked[id] = true
+	}
+
+	s.blockedMu.Lock()
+	s.blocked[userID] = blocked
+	s.blockedMu.Unlock()
+
+	return nil
+}
+
+// refreshBlockedLoop loads block list of user every blocksRefreshInterval until ctx is done (stream is closed),
+// then the list is forgotten. If it can't be loaded the last loaded list is kept.
+func (s *Service) refreshBlockedLoop(ctx context.Context, userID, token string) {
+	ticker := time.NewTicker(blocksRefreshInterval)
+	defer ticker.Stop()
+
+	defer func() {
+		s.blockedMu.Lock()
+		delete(s.blocked, userID)
+		s.blockedMu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := s.loadBlocked(ctx, userID, token)
+		if err != nil && ctx.Err() == nil {
+			s.l.Warn("Failed to refresh blocked users", slog.String("error", err.Error()))
+		}
+	}
+}
+
 func (s *Service) Stream(server chat.ChatService_StreamServer) error {
 	ctx := server.Context()
 
 	// 1. authenticate user using token and auth service
-	user, err := s.getUser(ctx)
+	user, token, err := s.getUser(ctx)
 	if err != nil {
 		return err
 	}
+	if !user.HasScope(models.ScopeChatRead) {
+		return status.Error(codes.PermissionDenied, "missing scope "+models.ScopeChatRead)
+	}
+
+	// 1.1 get display name of user, it is sent with every message
+	user.DisplayName = user.Username
+	profiles, err := s.authService.GetProfiles(ctx, []string{user.ID})
+	if err != nil {
+		// messages still can be sent, they will have username instead
+		s.l.Warn("Failed to get user profile", slog.String("error", err.Error()))
+	} else if p, ok := profiles[user.ID]; ok && p.GetDisplayName() != "" {
+		user.DisplayName = p.GetDisplayName()
+	}
+
+	// 1.2 get users blocked by user, their messages aren't sent to the stream
+	err = s.loadBlocked(ctx, user.ID, token)
+	if err != nil {
+		s.l.Error("Failed to get blocked users", slog.String("error", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+	go s.refreshBlockedLoop(ctx, user.ID, token)
 
 	// 2. save user as active user
 	s.userServers[user.ID] = server
@@ -125,6 +244,9 @@ func (s *Service) Stream(server chat.ChatService_StreamServer) error {
 			}
 
 			// 1. validate message
+			if !user.HasScope(models.ScopeChatWrite) {
+				return status.Error(codes.PermissionDenied, "missing scope "+models.ScopeChatWrite)
+			}
 			if req.GetMsg() == nil {
 				return status.Error(codes.InvalidArgument, "invalid message")
 			}
@@ -140,6 +262,8 @@ func (s *Service) Stream(server chat.ChatService_StreamServer) error {
 				ChatID: req.GetMsg().GetChatID(),
 				UserID: user.ID,
 				Text:   req.GetMsg().GetText(),
+
+				SenderDisplayName: user.DisplayName,
 			}
 			_, err = s.storage.CreateMessage(ctx, msg)
 			if err != nil {
@@ -155,23 +279,45 @@ func (s *Service) Stream(server chat.ChatService_StreamServer) error {
 }
 
 // getUser extracts user token from context and authenticates user using auth service
-// returns user if authenticated, otherwise returns error to be sent to client (status.Error)
-func (s *Service) getUser(ctx context.Context) (*models.User, error) {
+// returns user and token if authenticated, otherwise returns error to be sent to client (status.Error)
+func (s *Service) getUser(ctx context.Context) (*models.User, string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		return nil, "", status.Error(codes.Unauthenticated, "unauthenticated")
 	}
 	token, ok := md[TokenMetadataKey]
 	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		return nil, "", status.Error(codes.Unauthenticated, "unauthenticated")
 	}
-	user, err := s.authService.AuthenticateUser(ctx, []byte(token[0]))
+	user, err := s.authenticate(ctx, token[0])
 	if err != nil {
 		s.l.Error("Failed to authenticate user", slog.String("error", err.Error()))
-		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		return nil, "", status.Error(codes.Unauthenticated, "unauthenticated")
 	}
 	if user == nil {
-		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		return nil, "", status.Error(codes.Unauthenticated, "unauthenticated")
 	}
-	return user, nil
+	return user, token[0], nil
+}
+
+// authenticate verifies token locally if verifier is set,
+// auth service is called only when verifier doesn't know the key token is signed with.
+// Verifier still asks auth service about revocation of the token once per its check interval.
+func (s *Service) authenticate(ctx context.Context, token string) (*models.User, error) {
+	if s.tokenVerifier != nil && !strings.HasPrefix(token, APIKeyPrefix) {
+		c, err := s.tokenVerifier.Verify(ctx, token)
+		if err == nil {
+			return &models.User{
+				ID:             c.Subject,
+				Username:       c.Username,
+				Email:          c.Email,
+				ConfirmedEmail: c.Verified,
+			}, nil
+		}
+		if !errors.Is(err, token_verifier.ErrUnknownKey) {
+			return nil, err
+		}
+	}
+
+	return s.authService.AuthenticateUser(ctx, []byte(token))
 }
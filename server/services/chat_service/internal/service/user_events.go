@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	userEventsPage = 100
+
+	userEventDeleted = "user_deleted"
+)
+
+// ConsumeUserEventsLoop reads user events from auth service every interval until ctx is done.
+// Messages of deleted users are anonymized, handling is idempotent so events can be read twice.
+func (s *Service) ConsumeUserEventsLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := s.consumeUserEvents(ctx)
+		if err != nil {
+			s.l.Error("Failed to handle user events", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) consumeUserEvents(ctx context.Context) error {
+	cursor, err := s.storage.GetEventCursor(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		events, err := s.authService.ListUserEvents(ctx, cursor, userEventsPage)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			if e.GetType() == userEventDeleted {
+				err = s.storage.AnonymizeUserMessages(ctx, e.GetUserId())
+				if err != nil {
+					return err
+				}
+				s.l.Info("Anonymized messages of deleted user", slog.String("user_id", e.GetUserId()))
+			}
+
+			cursor = e.GetId()
+			err = s.storage.SetEventCursor(ctx, cursor)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(events) < userEventsPage {
+			return nil
+		}
+	}
+}
@@ -11,6 +11,15 @@ const (
 	ChatIDKey = "ChatID"
 	UserIDKey = "UserID"
 	TextKey   = "Text"
+
+	// userMessagesPrefix is prefix of sets with ids of messages sent by user
+	userMessagesPrefix = "user_messages:"
+	// eventCursorKey stores id of last user event handled by chat service
+	eventCursorKey = "user_events_cursor:chat_service"
+	// userMessagesBackfilledKey is set after messages sent before userMessagesPrefix sets existed were added to them
+	userMessagesBackfilledKey = "user_messages_backfilled"
+
+	backfillScanCount = 1000
 )
 
 type Storage struct {
@@ -28,12 +37,16 @@ func (s *Storage) CreateMessage(ctx context.Context, msg *models.Msg) (*models.M
 		return nil, err
 	}
 	msg.ID = id.String()
-	// 2. save message in redis
-	err = s.client.WithContext(ctx).HMSet(msg.ID, map[string]interface{}{
-		ChatIDKey: msg.ChatID,
-		UserIDKey: msg.UserID,
-		TextKey:   msg.Text,
-	}).Err()
+	// 2. save message in redis, id is also added to messages of the user
+	_, err = s.client.WithContext(ctx).TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HMSet(msg.ID, map[string]interface{}{
+			ChatIDKey: msg.ChatID,
+			UserIDKey: msg.UserID,
+			TextKey:   msg.Text,
+		})
+		pipe.SAdd(userMessagesPrefix+msg.UserID, msg.ID)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -57,5 +70,148 @@ func (s *Storage) GetMsgByID(ctx context.Context, id string) (*models.Msg, error
 }
 
 func (s *Storage) DeleteMsgByID(ctx context.Context, id string) error {
-	return s.client.WithContext(ctx).Del(id).Err()
+	client := s.client.WithContext(ctx)
+
+	userID, err := client.HGet(id, UserIDKey).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	_, err = client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Del(id)
+		if userID != "" {
+			pipe.SRem(userMessagesPrefix+userID, id)
+		}
+		return nil
+	})
+	return err
+}
+
+// GetUserMessages returns all messages sent by the user
+func (s *Storage) GetUserMessages(ctx context.Context, userID string) ([]*models.Msg, error) {
+	client := s.client.WithContext(ctx)
+
+	ids, err := client.SMembers(userMessagesPrefix + userID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]*models.Msg, 0, len(ids))
+	for _, id := range ids {
+		msg, err := client.HGetAll(id).Result()
+		if err != nil {
+			return nil, err
+		}
+		// message could be deleted already
+		if len(msg) == 0 {
+			continue
+		}
+		msgs = append(msgs, &models.Msg{
+			ID:     id,
+			ChatID: msg[ChatIDKey],
+			UserID: msg[UserIDKey],
+			Text:   msg[TextKey],
+		})
+	}
+
+	return msgs, nil
+}
+
+// AnonymizeUserMessages replaces author of all messages of the user with models.DeletedUserID,
+// text of messages is kept so conversations stay readable
+func (s *Storage) AnonymizeUserMessages(ctx context.Context, userID string) error {
+	client := s.client.WithContext(ctx)
+
+	ids, err := client.SMembers(userMessagesPrefix + userID).Result()
+	if err != nil {
+		return err
+	}
+
+	// message could be deleted already, HSet would create it again
+	existing := make([]string, 0, len(ids))
+	for _, id := range ids {
+		n, err := client.Exists(id).Result()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			existing = append(existing, id)
+		}
+	}
+
+	_, err = client.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, id := range existing {
+			pipe.HSet(id, UserIDKey, models.DeletedUserID)
+		}
+		pipe.Del(userMessagesPrefix + userID)
+		return nil
+	})
+	return err
+}
+
+// BackfillUserMessages adds messages sent before messages were indexed by author to sets of their authors,
+// so they are found by export and anonymization too. All keys are scanned only once, after that
+// userMessagesBackfilledKey is set and nothing is done. Returns number of indexed messages.
+func (s *Storage) BackfillUserMessages(ctx context.Context) (int, error) {
+	client := s.client.WithContext(ctx)
+
+	done, err := client.Exists(userMessagesBackfilledKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if done > 0 {
+		return 0, nil
+	}
+
+	var cursor uint64
+	n := 0
+	for {
+		keys, next, err := client.Scan(cursor, "", backfillScanCount).Result()
+		if err != nil {
+			return n, err
+		}
+
+		for _, key := range keys {
+			// messages are stored in hashes with id (uuid) as key
+			if _, err := uuid.Parse(key); err != nil {
+				continue
+			}
+			userID, err := client.HGet(key, UserIDKey).Result()
+			if err == redis.Nil {
+				continue
+			}
+			if err != nil {
+				return n, err
+			}
+			if userID == "" || userID == models.DeletedUserID {
+				continue
+			}
+
+			err = client.SAdd(userMessagesPrefix+userID, key).Err()
+			if err != nil {
+				return n, err
+			}
+			n++
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return n, client.Set(userMessagesBackfilledKey, 1, 0).Err()
+}
+
+// GetEventCursor returns id of last user event handled by chat service, 0 if there is none
+func (s *Storage) GetEventCursor(ctx context.Context) (int64, error) {
+	id, err := s.client.WithContext(ctx).Get(eventCursorKey).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return id, err
+}
+
+func (s *Storage) SetEventCursor(ctx context.Context, id int64) error {
+	return s.client.WithContext(ctx).Set(eventCursorKey, id, 0).Err()
 }
@@ -5,7 +5,7 @@ go 1.22.2
 require (
 	github.com/grpc-ecosystem/go-grpc-middleware/v2 v2.1.0
 	github.com/ilyakaznacheev/cleanenv v1.5.0
-	github.com/zumosik/grpc_chat_protos v0.0.0-20240416095555-8461c5201935
+	github.com/zumosik/grpc_chat_protos v0.0.0-00010101000000-000000000000
 	google.golang.org/grpc v1.63.2
 	gopkg.in/gomail.v2 v2.0.0-20160411212932-81ebce5c23df
 )
@@ -22,3 +22,5 @@ require (
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3 // indirect
 )
+
+replace github.com/zumosik/grpc_chat_protos => ../../protos
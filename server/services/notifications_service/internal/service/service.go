@@ -9,6 +9,7 @@ import (
 	"google.golang.org/grpc/status"
 	"gopkg.in/gomail.v2"
 	"log/slog"
+	"time"
 )
 
 type Service struct {
@@ -38,32 +39,80 @@ func (s *Service) SendNotification(
 	*notifications.NotificationResponse,
 	error,
 ) {
-	if req.GetConfirmEmail() != nil {
-		confirmEmail := req.GetConfirmEmail()
-		email := confirmEmail.GetEmail()
-		userID := confirmEmail.GetUserId()
+	var email, subject, body string
 
-		body := fmt.Sprintf(`
+	switch {
+	case req.GetConfirmEmail() != nil:
+		confirmEmail := req.GetConfirmEmail()
+		email = confirmEmail.GetEmail()
+		subject = "Confirmation Email"
+		body = fmt.Sprintf(`
     <h1>Confirmation code</h1>
     <p>Dear User,</p>
     <p>Thank you for signing up. Here is your confirmation code: </p>
     <p><strong>%s</strong></p>
-  `, userID)
-
-		m := gomail.NewMessage()
-		m.SetHeader("From", s.from)
-		m.SetHeader("To", email)
-		m.SetHeader("Subject", "Confirmation Email")
-		m.SetBody("text/html", body)
+  `, confirmEmail.GetVerificationCode())
+	case req.GetPasswordReset() != nil:
+		passwordReset := req.GetPasswordReset()
+		email = passwordReset.GetEmail()
+		subject = "Password Reset"
+		body = fmt.Sprintf(`
+    <h1>Password reset code</h1>
+    <p>Dear User,</p>
+    <p>Someone requested password reset for your account. Here is your code: </p>
+    <p><strong>%s</strong></p>
+    <p>If it wasn't you, just ignore this email.</p>
+  `, passwordReset.GetCode())
+	case req.GetAccountLocked() != nil:
+		accountLocked := req.GetAccountLocked()
+		email = accountLocked.GetEmail()
+		subject = "Account Locked"
+		body = fmt.Sprintf(`
+    <h1>Your account was temporarily locked</h1>
+    <p>Dear User,</p>
+    <p>There were too many failed login attempts to your account (last one from ip %s).</p>
+    <p>Login is blocked until <strong>%s</strong>.</p>
+    <p>If it wasn't you, consider resetting your password.</p>
+  `, accountLocked.GetIp(), time.Unix(accountLocked.GetLockedUntil(), 0).UTC().Format(time.RFC1123))
+	case req.GetDataExport() != nil:
+		dataExport := req.GetDataExport()
+		email = dataExport.GetEmail()
+		subject = "Your Data Export"
+		body = fmt.Sprintf(`
+    <h1>Your data export is ready</h1>
+    <p>Dear User,</p>
+    <p>Export of your personal data is ready to download.</p>
+    <p>Export id: <strong>%s</strong></p>
+    <p>Download code: <strong>%s</strong></p>
+    <p>It can be downloaded until <strong>%s</strong>.</p>
+    <p>If you didn't request it, consider changing your password.</p>
+  `, dataExport.GetExportId(), dataExport.GetCode(), time.Unix(dataExport.GetExpiresAt(), 0).UTC().Format(time.RFC1123))
+	case req.GetLoginCode() != nil:
+		loginCode := req.GetLoginCode()
+		email = loginCode.GetEmail()
+		subject = "Login Code"
+		body = fmt.Sprintf(`
+    <h1>Login code</h1>
+    <p>Dear User,</p>
+    <p>Here is your code to login without password: </p>
+    <p><strong>%s</strong></p>
+    <p>It is valid until <strong>%s</strong> and can be used only once.</p>
+    <p>If it wasn't you, just ignore this email.</p>
+  `, loginCode.GetCode(), time.Unix(loginCode.GetExpiresAt(), 0).UTC().Format(time.RFC1123))
+	default:
+		return &notifications.NotificationResponse{Status: "Email sent unsuccessfully"}, status.Error(codes.Unimplemented, "not implemented")
+	}
 
-		if err := s.dialer.DialAndSend(m); err != nil {
-			s.l.Error("cant send", slog.String("error", err.Error()))
-			return &notifications.NotificationResponse{Status: "Email sent unsuccessfully"}, status.Error(codes.Internal, "internal error sending email")
-		}
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.from)
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", body)
 
-		return &notifications.NotificationResponse{Status: "Email sent successfully"}, nil
+	if err := s.dialer.DialAndSend(m); err != nil {
+		s.l.Error("cant send", slog.String("error", err.Error()))
+		return &notifications.NotificationResponse{Status: "Email sent unsuccessfully"}, status.Error(codes.Internal, "internal error sending email")
 	}
 
-	return &notifications.NotificationResponse{Status: "Email sent unsuccessfully"}, status.Error(codes.Unimplemented, "not implemented")
-
+	return &notifications.NotificationResponse{Status: "Email sent successfully"}, nil
 }
@@ -23,6 +23,7 @@ import (
 	"rooms_service/internal/lib/logger/slogpretty"
 	"rooms_service/internal/service"
 	"rooms_service/internal/storage/sql/postgres"
+	"shared/token_verifier"
 	"syscall"
 )
 
@@ -55,11 +56,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	// verify tokens locally if it is enabled
+	var verifier interceptor.TokenVerifier
+	if cfg.Tokens.LocalVerification {
+		verifier = token_verifier.New(log, authClient, authClient, cfg.Tokens.CheckInterval)
+	}
+
 	// create storage
 	storage := postgres.New(db, authClient)
 
 	// create grpc server
-	serv := service.New(log, storage)
+	serv := service.New(log, storage, authClient)
 
 	loggingOpts := []logging.Option{
 		logging.WithLogOnEvents(
@@ -82,11 +89,16 @@ func main() {
 	gRPCServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
 		recovery.UnaryServerInterceptor(recoveryOpts...),
 		logging.UnaryServerInterceptor(InterceptorLogger(log), loggingOpts...),
-		interceptor.TokenMiddleware(authClient),
+		interceptor.TokenMiddleware(authClient, verifier),
 	), grpc.Creds(creds))
 
 	service.Register(gRPCServer, serv)
 
+	// Remove deleted users from rooms
+	eventsCtx, stopEvents := context.WithCancel(context.Background())
+	defer stopEvents()
+	go serv.ConsumeUserEventsLoop(eventsCtx, authClient, cfg.UserEvents.PollInterval)
+
 	// Start gRPC server
 	go func() {
 		l, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
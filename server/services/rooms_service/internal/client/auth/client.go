@@ -7,12 +7,16 @@ import (
 	"github.com/zumosik/grpc_chat_protos/go/auth"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"log/slog"
 	"os"
 	"rooms_service/internal/config"
 	"rooms_service/internal/models"
 )
 
+// tokenMetadataKey is name of token in metadata of requests to rooms service
+const tokenMetadataKey = "token"
+
 // Client is a client for PRIVATE auth service
 type Client struct {
 	l      *slog.Logger
@@ -78,9 +82,17 @@ func (c *Client) GetUserByToken(ctx context.Context, token string) (*models.User
 		Username: resp.User.Username,
 		Email:    resp.User.Email,
 		Verified: resp.User.Verified,
+		Scopes:   resp.GetScopes(),
 	}, nil
 }
 
+// CheckToken checks locally verified token with auth service,
+// error is returned if token was revoked or its user can't use it anymore
+func (c *Client) CheckToken(ctx context.Context, token string) error {
+	_, err := c.GetUserByToken(ctx, token)
+	return err
+}
+
 func (c *Client) GetUserByID(ctx context.Context, id string) (*models.User, error) {
 	resp, err := c.client.GetUserByID(ctx, &auth.GetUserByIDRequest{
 		Id: id,
@@ -94,5 +106,102 @@ func (c *Client) GetUserByID(ctx context.Context, id string) (*models.User, erro
 		Username: resp.User.Username,
 		Email:    resp.User.Email,
 		Verified: resp.User.Verified,
+		Scopes:   resp.GetScopes(),
 	}, nil
 }
+
+// GetUsersByIDs returns users with given ids by user id with one call,
+// users that don't exist (e.g. purged) are missing in map.
+// Users are requested on behalf of user whose token is in incoming metadata of ctx.
+func (c *Client) GetUsersByIDs(ctx context.Context, ids []string) (map[string]*models.User, error) {
+	resp, err := c.client.GetUsersByIDs(ctx, &auth.GetUsersByIDsRequest{
+		Ids:   ids,
+		Token: callerToken(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]*models.User, len(resp.GetUsers()))
+	for _, u := range resp.GetUsers() {
+		users[u.GetId()] = &models.User{
+			ID:       u.GetId(),
+			Username: u.GetUsername(),
+			Email:    u.GetEmail(),
+			Verified: u.GetVerified(),
+		}
+	}
+
+	return users, nil
+}
+
+// IsBlocked reports if one of users blocked the other one,
+// userID must be id of user whose token is in incoming metadata of ctx
+func (c *Client) IsBlocked(ctx context.Context, userID, otherID string) (bool, error) {
+	resp, err := c.client.GetBlockRelations(ctx, &auth.GetBlockRelationsRequest{
+		UserId:       userID,
+		OtherUserIds: []string{otherID},
+		Token:        callerToken(ctx),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return len(resp.GetBlockedBy()) > 0 || len(resp.GetBlocked()) > 0, nil
+}
+
+func (c *Client) GetSigningKeys(ctx context.Context) ([]*auth.SigningKey, error) {
+	resp, err := c.client.GetSigningKeys(ctx, &auth.GetSigningKeysRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetKeys(), nil
+}
+
+// GetProfiles returns profiles of users with given ids by user id,
+// users that don't exist are missing in map
+func (c *Client) GetProfiles(ctx context.Context, ids []string) (map[string]*auth.Profile, error) {
+	resp, err := c.client.GetProfiles(ctx, &auth.GetProfilesRequest{
+		UserIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]*auth.Profile, len(resp.GetProfiles()))
+	for _, p := range resp.GetProfiles() {
+		profiles[p.GetUserId()] = p
+	}
+
+	return profiles, nil
+}
+
+// ListUserEvents returns events about users (e.g. deleted users) with id greater than afterID
+func (c *Client) ListUserEvents(ctx context.Context, afterID int64, limit int) ([]*auth.UserEvent, error) {
+	resp, err := c.client.ListUserEvents(ctx, &auth.ListUserEventsRequest{
+		AfterId: afterID,
+		Limit:   int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetEvents(), nil
+}
+
+// callerToken returns token of user who called rooms service, it is taken from incoming metadata
+// (see interceptor.TokenMetadataKey), so auth service can check what the user is allowed to see
+func callerToken(ctx context.Context) *auth.Token {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	tokens := md.Get(tokenMetadataKey)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	return &auth.Token{Token: []byte(tokens[0])}
+}
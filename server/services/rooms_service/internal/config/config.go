@@ -4,13 +4,16 @@ import (
 	"flag"
 	"github.com/ilyakaznacheev/cleanenv"
 	"os"
+	"time"
 )
 
 type Config struct {
 	Env           string        `yaml:"env" env-default:"local"`
 	Storage       StorageConfig `yaml:"storage_cfg" env-required:"true"`
 	GRPC          GRPCConfig    `yaml:"grpc" env-required:"true"`
+	Tokens        TokensConfig  `yaml:"tokens"`
 	OtherServices OtherServices `yaml:"other_services" env-required:"true"`
+	UserEvents    UserEvents    `yaml:"user_events"`
 }
 
 type GRPCConfig struct {
@@ -24,6 +27,20 @@ type CertsConfig struct {
 	KeyPath  string `yaml:"key_path" env-required:"true"`
 }
 
+type TokensConfig struct {
+	// LocalVerification enables verifying tokens with public keys of auth service
+	// instead of calling it on every request
+	LocalVerification bool `yaml:"local_verification" env-default:"false"`
+	// CheckInterval is how often locally verified token is checked with auth service,
+	// revoked tokens and tokens of suspended or deleted users are accepted at most this long
+	CheckInterval time.Duration `yaml:"check_interval" env-default:"30s"`
+}
+
+// UserEvents configures reading events about users (e.g. deleted users) from auth service
+type UserEvents struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"30s"`
+}
+
 type StorageConfig struct {
 	PostgresURl string `yaml:"postgres_url" env-required:"true"`
 }
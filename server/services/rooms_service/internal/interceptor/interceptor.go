@@ -2,11 +2,14 @@ package interceptor
 
 import (
 	"context"
+	"errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"rooms_service/internal/models"
+	"shared/token_verifier"
+	"strings"
 )
 
 type UserContextKeyType string
@@ -14,23 +17,30 @@ type UserContextKeyType string
 const (
 	TokenMetadataKey                    = "token"
 	UserContextKey   UserContextKeyType = "user"
+
+	// APIKeyPrefix is prefix of api keys issued by auth service, they can be checked only by auth service
+	APIKeyPrefix = "cgk_"
 )
 
 type AuthServiceClient interface {
 	GetUserByToken(ctx context.Context, token string) (*models.User, error)
 }
 
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*token_verifier.Claims, error)
+}
+
 // TokenMiddleware is func that returns a middleware that extracts the token from the metadata of incoming gRPC requests,
-// sends it to another service, and saves the user in the context
-func TokenMiddleware(cl AuthServiceClient) grpc.UnaryServerInterceptor {
+// verifies it (locally if v isn't nil, otherwise using another service), and saves the user in the context
+func TokenMiddleware(cl AuthServiceClient, v TokenVerifier) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		token, ok := extractToken(ctx)
 		if !ok {
 			return nil, status.Error(codes.Unauthenticated, "No token found in the request metadata")
 		}
 
-		// Send the token to another service and save the user
-		user, err := cl.GetUserByToken(ctx, token)
+		// Verify the token and save the user
+		user, err := authenticate(ctx, cl, v, token)
 		if err != nil {
 			return nil, status.Error(codes.Unauthenticated, "Invalid token")
 		}
@@ -45,6 +55,28 @@ func TokenMiddleware(cl AuthServiceClient) grpc.UnaryServerInterceptor {
 	}
 }
 
+// authenticate verifies token locally if verifier is set,
+// auth service is called only when verifier doesn't know the key token is signed with.
+// Verifier still asks auth service about revocation of the token once per its check interval.
+func authenticate(ctx context.Context, cl AuthServiceClient, v TokenVerifier, token string) (*models.User, error) {
+	if v != nil && !strings.HasPrefix(token, APIKeyPrefix) {
+		c, err := v.Verify(ctx, token)
+		if err == nil {
+			return &models.User{
+				ID:       c.Subject,
+				Username: c.Username,
+				Email:    c.Email,
+				Verified: c.Verified,
+			}, nil
+		}
+		if !errors.Is(err, token_verifier.ErrUnknownKey) {
+			return nil, err
+		}
+	}
+
+	return cl.GetUserByToken(ctx, token)
+}
+
 // extractToken extracts the token from the metadata of the incoming gRPC request
 // and returns it along with a boolean indicating whether the token was found
 // name in metadata must be TokenMetadataKey
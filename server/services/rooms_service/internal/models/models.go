@@ -2,11 +2,50 @@ package models
 
 import "github.com/zumosik/grpc_chat_protos/go/rooms"
 
+// Scopes of api keys (see auth service)
+const (
+	ScopeRoomsRead  = "rooms:read"
+	ScopeRoomsWrite = "rooms:write"
+)
+
+// DeletedUsername is shown instead of username of users that don't exist anymore
+const DeletedUsername = "deleted user"
+
 type User struct {
 	ID       string
 	Username string
 	Email    string
 	Verified bool
+
+	// DisplayName and AvatarRef are from user profile, filled only when room members are rendered
+	DisplayName string
+	AvatarRef   string
+
+	// Scopes are set only when user is authenticated with api key,
+	// nil means that user isn't limited
+	Scopes []string
+}
+
+// DeletedUser returns placeholder for user that doesn't exist anymore (e.g. purged)
+func DeletedUser(id string) *User {
+	return &User{
+		ID:          id,
+		Username:    DeletedUsername,
+		DisplayName: DeletedUsername,
+	}
+}
+
+// HasScope reports if user can do actions allowed by scope
+func (u *User) HasScope(scope string) bool {
+	if u.Scopes == nil {
+		return true
+	}
+	for _, s := range u.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
 
 type Room struct {
@@ -20,10 +59,12 @@ func (r *Room) ToProto() *rooms.Room {
 	users := make([]*rooms.User, 0, len(r.Users))
 	for _, user := range r.Users {
 		users = append(users, &rooms.User{
-			Id:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Verified: user.Verified,
+			Id:          user.ID,
+			Username:    user.Username,
+			Email:       user.Email,
+			Verified:    user.Verified,
+			DisplayName: user.DisplayName,
+			AvatarRef:   user.AvatarRef,
 		})
 	}
 
@@ -48,3 +89,12 @@ func (r *Room) HasUser(u *User) bool {
 	}
 	return false
 }
+
+// ExportedRoom is room in personal data export of the user, other members aren't included
+type ExportedRoom struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Member       bool   `json:"member"` // false if the user created room and left it
+	CreatedByMe  bool   `json:"created_by_me"`
+	MembersCount int    `json:"members_count"`
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/zumosik/grpc_chat_protos/go/rooms"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"rooms_service/internal/interceptor"
+	"rooms_service/internal/models"
+)
+
+// ExportUserData returns JSON with rooms of the user for personal data export (see auth service)
+func (s *Service) ExportUserData(ctx context.Context, _ *rooms.ExportUserDataRequest) (*rooms.ExportUserDataResponse, error) {
+	u, ok := ctx.Value(interceptor.UserContextKey).(*models.User)
+	if !ok {
+		s.l.Error("Cant get user from context")
+		return nil, status.Error(codes.Internal, "Cant authenticate user")
+	}
+	if !u.HasScope(models.ScopeRoomsRead) {
+		return nil, status.Error(codes.PermissionDenied, "Missing scope "+models.ScopeRoomsRead)
+	}
+
+	userRooms, err := s.storage.GetRoomsForExport(ctx, u.ID)
+	if err != nil {
+		s.l.Error("Cant get rooms for export", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "Cant get rooms")
+	}
+
+	data, err := json.MarshalIndent(struct {
+		UserID string                `json:"user_id"`
+		Rooms  []models.ExportedRoom `json:"rooms"`
+	}{u.ID, userRooms}, "", "  ")
+	if err != nil {
+		s.l.Error("Cant marshal rooms", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "Cant get rooms")
+	}
+
+	return &rooms.ExportUserDataResponse{Data: data}, nil
+}
@@ -20,18 +20,29 @@ type RoomStorage interface {
 	DeleteRoom(ctx context.Context, id string) error
 
 	GetRoomsByUser(ctx context.Context, u *models.User) ([]*models.Room, error)
+	GetRoomsForExport(ctx context.Context, userID string) ([]models.ExportedRoom, error)
+
+	RemoveUserFromRooms(ctx context.Context, userID string) error
+	GetEventCursor(ctx context.Context, consumer string) (int64, error)
+	SetEventCursor(ctx context.Context, consumer string, id int64) error
+}
+
+type AuthService interface {
+	GetUsersByIDs(ctx context.Context, ids []string) (map[string]*models.User, error)
+	IsBlocked(ctx context.Context, userID, otherID string) (bool, error)
 }
 
 type Service struct {
 	l *slog.Logger
 
-	storage RoomStorage
+	storage     RoomStorage
+	authService AuthService
 
 	rooms.UnimplementedRoomServiceServer
 }
 
-func New(l *slog.Logger, storage RoomStorage) *Service {
-	return &Service{l: l, storage: storage}
+func New(l *slog.Logger, storage RoomStorage, authService AuthService) *Service {
+	return &Service{l: l, storage: storage, authService: authService}
 }
 
 func Register(server *grpc.Server, service *Service) {
@@ -44,6 +55,9 @@ func (s *Service) CreateRoom(ctx context.Context, req *rooms.CreateRoomRequest)
 		s.l.Error("Cant get user from context")
 		return nil, status.Error(codes.Internal, "Cant authenticate user")
 	}
+	if !u.HasScope(models.ScopeRoomsWrite) {
+		return nil, status.Error(codes.PermissionDenied, "Missing scope "+models.ScopeRoomsWrite)
+	}
 
 	room := &models.Room{
 		Name:      req.Name,
@@ -65,6 +79,9 @@ func (s *Service) GetRoom(ctx context.Context, req *rooms.GetRoomRequest) (*room
 		s.l.Error("Cant get user from context")
 		return nil, status.Error(codes.Internal, "Cant authenticate user")
 	}
+	if !u.HasScope(models.ScopeRoomsRead) {
+		return nil, status.Error(codes.PermissionDenied, "Missing scope "+models.ScopeRoomsRead)
+	}
 
 	room, err := s.storage.GetRoom(ctx, req.RoomId)
 	if err != nil {
@@ -85,6 +102,9 @@ func (s *Service) UpdateRoom(ctx context.Context, req *rooms.UpdateRoomRequest)
 		s.l.Error("Cant get user from context")
 		return nil, status.Error(codes.Internal, "Cant authenticate user")
 	}
+	if !u.HasScope(models.ScopeRoomsWrite) {
+		return nil, status.Error(codes.PermissionDenied, "Missing scope "+models.ScopeRoomsWrite)
+	}
 
 	room, err := s.storage.GetRoom(ctx, req.RoomId)
 	if err != nil {
@@ -115,6 +135,9 @@ func (s *Service) DeleteRoom(ctx context.Context, req *rooms.DeleteRoomRequest)
 		s.l.Error("Cant get user from context")
 		return nil, status.Error(codes.Internal, "Cant authenticate user")
 	}
+	if !u.HasScope(models.ScopeRoomsWrite) {
+		return nil, status.Error(codes.PermissionDenied, "Missing scope "+models.ScopeRoomsWrite)
+	}
 
 	room, err := s.storage.GetRoom(ctx, req.RoomId)
 	if err != nil {
@@ -141,6 +164,9 @@ func (s *Service) AddToRoom(ctx context.Context, req *rooms.AddToRoomRequest) (*
 		s.l.Error("Cant get user from context")
 		return nil, status.Error(codes.Internal, "Cant authenticate user")
 	}
+	if !u.HasScope(models.ScopeRoomsWrite) {
+		return nil, status.Error(codes.PermissionDenied, "Missing scope "+models.ScopeRoomsWrite)
+	}
 
 	room, err := s.storage.GetRoom(ctx, req.RoomId)
 	if err != nil {
@@ -148,11 +174,23 @@ func (s *Service) AddToRoom(ctx context.Context, req *rooms.AddToRoomRequest) (*
 		return nil, status.Error(codes.NotFound, "Room not found")
 	}
 
-	if room.HasUser(u) {
+	// user joins the room, or invites other user if id is set
+	target := u
+	if req.UserId != "" && req.UserId != u.ID {
+		if !room.HasUser(u) {
+			return nil, status.Error(codes.PermissionDenied, "User is not in the room")
+		}
+		target, err = s.invitee(ctx, u, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if room.HasUser(target) {
 		return nil, status.Error(codes.AlreadyExists, "User is already in the room")
 	}
 
-	room.Users = append(room.Users, u)
+	room.Users = append(room.Users, target)
 
 	roomResp, err := s.storage.UpdateRoom(ctx, room)
 	if err != nil {
@@ -163,12 +201,41 @@ func (s *Service) AddToRoom(ctx context.Context, req *rooms.AddToRoomRequest) (*
 	return &rooms.AddToRoomResponse{Room: roomResp.ToProto()}, nil
 }
 
+// invitee finds user invited by u, users can't invite each other if one of them blocked the other one.
+// Returned error is already status.Error
+func (s *Service) invitee(ctx context.Context, u *models.User, id string) (*models.User, error) {
+	users, err := s.authService.GetUsersByIDs(ctx, []string{id})
+	if err != nil {
+		s.l.Error("Cant get user", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "Cant get user")
+	}
+	invitee, ok := users[id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "User not found")
+	}
+
+	blocked, err := s.authService.IsBlocked(ctx, u.ID, invitee.ID)
+	if err != nil {
+		s.l.Error("Cant check blocked users", slog.String("error", err.Error()))
+		return nil, status.Error(codes.Internal, "Cant check blocked users")
+	}
+	if blocked {
+		// it isn't told who blocked whom
+		return nil, status.Error(codes.PermissionDenied, "User cant be invited")
+	}
+
+	return invitee, nil
+}
+
 func (s *Service) DeleteFromRoom(ctx context.Context, req *rooms.AddToRoomRequest) (*rooms.AddToRoomResponse, error) {
 	u, ok := ctx.Value(interceptor.UserContextKey).(*models.User)
 	if !ok {
 		s.l.Error("Cant get user from context")
 		return nil, status.Error(codes.Internal, "Cant authenticate user")
 	}
+	if !u.HasScope(models.ScopeRoomsWrite) {
+		return nil, status.Error(codes.PermissionDenied, "Missing scope "+models.ScopeRoomsWrite)
+	}
 
 	room, err := s.storage.GetRoom(ctx, req.RoomId)
 	if err != nil {
@@ -204,6 +271,9 @@ func (s *Service) GetRoomsByUser(ctx context.Context, req *rooms.GetRoomsByUserR
 		s.l.Error("Cant get user from context")
 		return nil, status.Error(codes.Internal, "Cant authenticate user")
 	}
+	if !u.HasScope(models.ScopeRoomsRead) {
+		return nil, status.Error(codes.PermissionDenied, "Missing scope "+models.ScopeRoomsRead)
+	}
 
 	userRooms, err := s.storage.GetRoomsByUser(ctx, u)
 	if err != nil {
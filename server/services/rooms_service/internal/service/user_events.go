@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+	"log/slog"
+	"time"
+)
+
+const (
+	// userEventsConsumer is name of rooms service in user_event_cursors
+	userEventsConsumer = "rooms_service"
+	userEventsPage     = 100
+
+	userEventDeleted = "user_deleted"
+)
+
+type UserEventsSource interface {
+	ListUserEvents(ctx context.Context, afterID int64, limit int) ([]*auth.UserEvent, error)
+}
+
+// ConsumeUserEventsLoop reads user events from auth service every interval until ctx is done.
+// Memberships of deleted users are removed, handling is idempotent so events can be read twice.
+func (s *Service) ConsumeUserEventsLoop(ctx context.Context, source UserEventsSource, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := s.consumeUserEvents(ctx, source)
+		if err != nil {
+			s.l.Error("Cant handle user events", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) consumeUserEvents(ctx context.Context, source UserEventsSource) error {
+	cursor, err := s.storage.GetEventCursor(ctx, userEventsConsumer)
+	if err != nil {
+		return err
+	}
+
+	for {
+		events, err := source.ListUserEvents(ctx, cursor, userEventsPage)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			if e.GetType() == userEventDeleted {
+				err = s.storage.RemoveUserFromRooms(ctx, e.GetUserId())
+				if err != nil {
+					return err
+				}
+				s.l.Info("Removed deleted user from rooms", slog.String("user_id", e.GetUserId()))
+			}
+
+			cursor = e.GetId()
+			err = s.storage.SetEventCursor(ctx, userEventsConsumer, cursor)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(events) < userEventsPage {
+			return nil
+		}
+	}
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zumosik/grpc_chat_protos/go/auth"
 	"rooms_service/internal/models"
 )
 
+// authBatchSize is max number of ids in one batch request to auth service
+const authBatchSize = 1000
+
 type AuthServiceClient interface {
-	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, ids []string) (map[string]*models.User, error)
+	GetProfiles(ctx context.Context, ids []string) (map[string]*auth.Profile, error)
 }
 
 // Storage works with postgres db and auth service
@@ -38,7 +44,7 @@ func (s *Storage) CreateRoom(ctx context.Context, room *models.Room) (*models.Ro
 		userIDs = append(userIDs, u.ID)
 	}
 
-	_, err = s.db.ExecContext(ctx, query, room.ID, room.Name, userIDs, room.CreatedBy.ID)
+	_, err = s.db.ExecContext(ctx, query, room.ID, room.Name, pq.Array(userIDs), room.CreatedBy.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -52,25 +58,18 @@ func (s *Storage) GetRoom(ctx context.Context, id string) (*models.Room, error)
 	SELECT id, name, user_ids, created_by_id FROM rooms WHERE id = $1
 `
 
-	var room models.Room
-	var userIDs []string
-
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&room.ID, &room.Name, &userIDs, &room.CreatedBy.ID)
+	var row roomRow
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&row.id, &row.name, pq.Array(&row.userIDs), &row.createdByID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get users
-	room.Users = make([]*models.User, 0, len(userIDs))
-	for _, id := range userIDs {
-		u, err := s.authClient.GetUserByID(ctx, id)
-		if err != nil {
-			return nil, err
-		}
-		room.Users = append(room.Users, u)
+	rooms, err := s.loadUsers(ctx, []roomRow{row})
+	if err != nil {
+		return nil, err
 	}
 
-	return &room, nil
+	return rooms[0], nil
 }
 
 func (s *Storage) UpdateRoom(ctx context.Context, room *models.Room) (*models.Room, error) {
@@ -83,7 +82,7 @@ func (s *Storage) UpdateRoom(ctx context.Context, room *models.Room) (*models.Ro
 		userIDs = append(userIDs, u.ID)
 	}
 
-	_, err := s.db.ExecContext(ctx, query, room.Name, userIDs, room.CreatedBy.ID, room.ID)
+	_, err := s.db.ExecContext(ctx, query, room.Name, pq.Array(userIDs), room.CreatedBy.ID, room.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -119,28 +118,152 @@ func (s *Storage) GetRoomsByUser(ctx context.Context, u *models.User) ([]*models
 		_ = rows.Close()
 	}(rows)
 
-	rooms := make([]*models.Room, 0)
+	roomRows := make([]roomRow, 0)
 	for rows.Next() {
-		var room models.Room
-		var userIDs []string
+		var row roomRow
 
-		err := rows.Scan(&room.ID, &room.Name, &userIDs, &room.CreatedBy.ID)
+		err := rows.Scan(&row.id, &row.name, pq.Array(&row.userIDs), &row.createdByID)
 		if err != nil {
 			return nil, err
 		}
 
-		// Get users for each room
-		room.Users = make([]*models.User, 0, len(userIDs))
-		for _, id := range userIDs {
-			u, err := s.authClient.GetUserByID(ctx, id)
-			if err != nil {
-				return nil, err
-			}
-			room.Users = append(room.Users, u)
+		roomRows = append(roomRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get users of all rooms at once
+	return s.loadUsers(ctx, roomRows)
+}
+
+// roomRow is room as it is stored, users are only ids
+type roomRow struct {
+	id          string
+	name        string
+	userIDs     []string
+	createdByID string
+}
+
+// loadUsers creates rooms from rows, members and creators of all rooms are requested
+// from auth service in batches. Users that don't exist anymore are replaced with models.DeletedUser.
+func (s *Storage) loadUsers(ctx context.Context, rows []roomRow) ([]*models.Room, error) {
+	// 1. Collect unique ids
+	seen := make(map[string]bool)
+	ids := make([]string, 0)
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, row := range rows {
+		for _, id := range row.userIDs {
+			add(id)
 		}
+		add(row.createdByID)
+	}
 
-		rooms = append(rooms, &room)
+	// 2. Get users
+	users := make(map[string]*models.User, len(ids))
+	for start := 0; start < len(ids); start += authBatchSize {
+		end := min(start+authBatchSize, len(ids))
+		batch, err := s.authClient.GetUsersByIDs(ctx, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for id, u := range batch {
+			users[id] = u
+		}
+	}
+
+	existing := make([]*models.User, 0, len(users))
+	for _, u := range users {
+		existing = append(existing, u)
+	}
+	err := s.fillProfiles(ctx, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	// users that don't exist anymore (e.g. purged) don't fail the request
+	for _, id := range ids {
+		if _, ok := users[id]; !ok {
+			users[id] = models.DeletedUser(id)
+		}
+	}
+
+	// 3. Create rooms
+	rooms := make([]*models.Room, 0, len(rows))
+	for _, row := range rows {
+		room := &models.Room{
+			ID:        row.id,
+			Name:      row.name,
+			Users:     make([]*models.User, 0, len(row.userIDs)),
+			CreatedBy: users[row.createdByID],
+		}
+		for _, id := range row.userIDs {
+			room.Users = append(room.Users, users[id])
+		}
+		rooms = append(rooms, room)
 	}
 
 	return rooms, nil
 }
+
+// fillProfiles sets display names and avatars of users with as few calls to auth service as possible
+func (s *Storage) fillProfiles(ctx context.Context, users []*models.User) error {
+	for start := 0; start < len(users); start += authBatchSize {
+		end := min(start+authBatchSize, len(users))
+
+		ids := make([]string, 0, end-start)
+		for _, u := range users[start:end] {
+			ids = append(ids, u.ID)
+		}
+
+		profiles, err := s.authClient.GetProfiles(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range users[start:end] {
+			if p, ok := profiles[u.ID]; ok {
+				u.DisplayName = p.GetDisplayName()
+				u.AvatarRef = p.GetAvatarRef()
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetRoomsForExport returns rooms the user is member of or created,
+// auth service isn't called because data of other members isn't exported
+func (s *Storage) GetRoomsForExport(ctx context.Context, userID string) ([]models.ExportedRoom, error) {
+	query := `
+	SELECT id, name, $1 = ANY(user_ids), created_by_id = $1, cardinality(user_ids) FROM rooms
+	WHERE $1 = ANY(user_ids) OR created_by_id = $1
+	ORDER BY name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	rooms := make([]models.ExportedRoom, 0)
+	for rows.Next() {
+		var room models.ExportedRoom
+		err := rows.Scan(&room.ID, &room.Name, &room.Member, &room.CreatedByMe, &room.MembersCount)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+
+	return rooms, rows.Err()
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// RemoveUserFromRooms removes user from members of all rooms, rooms without members are deleted
+func (s *Storage) RemoveUserFromRooms(ctx context.Context, userID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+	UPDATE rooms SET user_ids = array_remove(user_ids, $1) WHERE $1 = ANY(user_ids)
+`
+	_, err = tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	query = `
+	DELETE FROM rooms WHERE cardinality(user_ids) = 0
+`
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetEventCursor returns id of last user event handled by consumer, 0 if there is none
+func (s *Storage) GetEventCursor(ctx context.Context, consumer string) (int64, error) {
+	query := `
+	SELECT last_event_id FROM user_event_cursors WHERE consumer = $1
+`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query, consumer).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *Storage) SetEventCursor(ctx context.Context, consumer string, id int64) error {
+	query := `
+	INSERT INTO user_event_cursors (consumer, last_event_id) VALUES ($1, $2)
+	ON CONFLICT (consumer) DO UPDATE SET last_event_id = EXCLUDED.last_event_id
+`
+
+	_, err := s.db.ExecContext(ctx, query, consumer, id)
+	return err
+}
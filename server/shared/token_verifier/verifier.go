@@ -0,0 +1,255 @@
+package token_verifier
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/zumosik/grpc_chat_protos/go/auth"
+)
+
+// minRefreshInterval limits how often keys are fetched from auth service,
+// so tokens with random kid can't be used to flood it
+const minRefreshInterval = time.Minute
+
+// ErrUnknownKey is returned when token is signed with key that auth service doesn't publish
+// (e.g. symmetric key or key that was rotated out), such tokens must be checked by auth service itself
+var ErrUnknownKey = errors.New("token is signed with unknown key")
+
+type KeySource interface {
+	GetSigningKeys(ctx context.Context) ([]*auth.SigningKey, error)
+}
+
+// TokenChecker checks token with auth service: token and its session must not be revoked
+// and its user must not be suspended or deleted. Error is returned if token must be rejected.
+type TokenChecker interface {
+	CheckToken(ctx context.Context, token string) error
+}
+
+// Claims must match claims created by auth service
+type Claims struct {
+	Username  string `json:"username,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Verified  bool   `json:"email_verified,omitempty"`
+	Role      string `json:"role,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	jwt.StandardClaims
+}
+
+type publicKey struct {
+	alg string
+	key interface{}
+}
+
+// Verifier verifies tokens locally using public keys of auth service.
+// Signature and expiration are checked on every call, revocation can be checked only by auth service,
+// so every token is checked with it again when checkInterval passed since its last check.
+type Verifier struct {
+	l       *slog.Logger
+	src     KeySource
+	checker TokenChecker
+
+	mu   sync.RWMutex
+	keys map[string]publicKey
+
+	// refreshMu is held while keys are fetched, so only one request goes to auth service
+	// and tokens signed with known keys are verified in the meantime
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
+
+	checkInterval time.Duration
+	checkedMu     sync.Mutex
+	checked       map[string]time.Time // jti -> time until which token doesn't have to be checked
+	lastCleanup   time.Time
+}
+
+func New(log *slog.Logger, src KeySource, checker TokenChecker, checkInterval time.Duration) *Verifier {
+	return &Verifier{
+		l:             log,
+		src:           src,
+		checker:       checker,
+		keys:          make(map[string]publicKey),
+		checkInterval: checkInterval,
+		checked:       make(map[string]time.Time),
+	}
+}
+
+// Verify verifies token signature and expiration and returns its claims.
+// Keys are fetched from auth service again when token has unknown kid,
+// if kid is still unknown ErrUnknownKey is returned.
+// Error of TokenChecker is returned as is if token was rejected by auth service.
+func (v *Verifier) Verify(ctx context.Context, tokenStr string) (*Claims, error) {
+	c := &Claims{}
+
+	_, err := jwt.ParseWithClaims(tokenStr, c, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		k, ok := v.getKey(ctx, kid)
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+
+		// never let token choose the algorithm
+		if token.Method.Alg() != k.alg {
+			return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+		}
+
+		return k.key, nil
+	})
+	if err != nil {
+		var vErr *jwt.ValidationError
+		if errors.As(err, &vErr) && errors.Is(vErr.Inner, ErrUnknownKey) {
+			return nil, ErrUnknownKey
+		}
+		return nil, err
+	}
+
+	err = v.check(ctx, tokenStr, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// check checks token with auth service if it wasn't checked in last checkInterval
+func (v *Verifier) check(ctx context.Context, tokenStr string, c *Claims) error {
+	now := time.Now()
+
+	v.checkedMu.Lock()
+	until, ok := v.checked[c.Id]
+	v.checkedMu.Unlock()
+	if ok && now.Before(until) {
+		return nil
+	}
+
+	err := v.checker.CheckToken(ctx, tokenStr)
+	if err != nil {
+		return err
+	}
+
+	// tokens without jti can't be told apart, they are checked every time
+	if c.Id == "" {
+		return nil
+	}
+
+	until = now.Add(v.checkInterval)
+	if exp := time.Unix(c.ExpiresAt, 0); exp.Before(until) {
+		until = exp
+	}
+
+	v.checkedMu.Lock()
+	defer v.checkedMu.Unlock()
+
+	// forget tokens which check expired, so map doesn't keep every token ever seen
+	if now.Sub(v.lastCleanup) >= v.checkInterval {
+		for jti, u := range v.checked {
+			if !now.Before(u) {
+				delete(v.checked, jti)
+			}
+		}
+		v.lastCleanup = now
+	}
+
+	v.checked[c.Id] = until
+
+	return nil
+}
+
+func (v *Verifier) getKey(ctx context.Context, kid string) (publicKey, bool) {
+	if kid == "" {
+		return publicKey{}, false
+	}
+
+	v.mu.RLock()
+	k, ok := v.keys[kid]
+	v.mu.RUnlock()
+	if ok {
+		return k, true
+	}
+
+	v.refresh(ctx)
+
+	v.mu.RLock()
+	k, ok = v.keys[kid]
+	v.mu.RUnlock()
+
+	return k, ok
+}
+
+// refresh replaces known keys with keys from auth service,
+// keys are locked only to swap them, not while waiting for auth service
+func (v *Verifier) refresh(ctx context.Context) {
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+
+	if time.Since(v.lastRefresh) < minRefreshInterval {
+		return
+	}
+	v.lastRefresh = time.Now()
+
+	jwks, err := v.src.GetSigningKeys(ctx)
+	if err != nil {
+		v.l.Error("Cant get signing keys from auth service", slog.String("error", err.Error()))
+		return
+	}
+
+	keys := make(map[string]publicKey, len(jwks))
+	for _, jwk := range jwks {
+		k, err := parseJWK(jwk)
+		if err != nil {
+			v.l.Warn("Cant parse signing key", slog.String("kid", jwk.GetKid()), slog.String("error", err.Error()))
+			continue
+		}
+		keys[jwk.GetKid()] = k
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+
+	v.l.Debug("Signing keys refreshed", slog.Int("count", len(keys)))
+}
+
+func parseJWK(jwk *auth.SigningKey) (publicKey, error) {
+	switch jwk.GetKty() {
+	case "OKP":
+		if jwk.GetCrv() != "Ed25519" {
+			return publicKey{}, fmt.Errorf("unsupported curve %q", jwk.GetCrv())
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.GetX())
+		if err != nil {
+			return publicKey{}, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return publicKey{}, errors.New("invalid ed25519 key size")
+		}
+
+		return publicKey{alg: jwt.SigningMethodEdDSA.Alg(), key: ed25519.PublicKey(x)}, nil
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.GetN())
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.GetE())
+		if err != nil {
+			return publicKey{}, err
+		}
+
+		return publicKey{alg: jwt.SigningMethodRS256.Alg(), key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key type %q", jwk.GetKty())
+	}
+}